@@ -0,0 +1,264 @@
+package libgit2
+
+/*
+#include <string.h>
+#include "libgit2.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"unsafe"
+)
+
+// Blob is the representation of a blob object, the contents of a file.
+type Blob struct {
+	*gitBlob
+}
+
+// Close releases the blob. It may be called more than once.
+func (b Blob) Close() error {
+	b.free()
+	return nil
+}
+
+// ID is the object ID of the blob.
+func (b Blob) ID() OID {
+	if b.ptr == nil {
+		return OID{}
+	}
+	return gitBlobID(b.gitBlob)
+}
+
+// IsBinary reports whether the contents of the blob look like binary data,
+// using the same heuristic as git.
+func (b Blob) IsBinary() bool {
+	if b.ptr == nil {
+		return false
+	}
+	return gitBlobIsBinary(b.gitBlob)
+}
+
+// Peel returns the blob itself for ObjectBlob or ObjectAny, and fails for
+// any other type.
+func (b Blob) Peel(t ObjectType) (Object, error) {
+	return peelObject((*C.git_object)(unsafe.Pointer(b.ptr)), b.repo, t)
+}
+
+// Reader returns a reader for the contents of the blob, which also implements
+// io.Seeker. The contents are read directly from the libgit2 object, without
+// copying them into Go memory first. The reader stays valid after the blob is
+// closed, and must be closed itself when done.
+func (b Blob) Reader() (io.ReadCloser, error) {
+	if b.ptr == nil {
+		return nil, errObjectClosed
+	}
+	blob, err := gitBlobDup(b.gitBlob)
+	if err != nil {
+		return nil, err
+	}
+	return &blobReader{blob: blob, size: gitBlobRawsize(blob)}, nil
+}
+
+// Size is the size of the contents of the blob, in bytes.
+func (b Blob) Size() int64 {
+	if b.ptr == nil {
+		return 0
+	}
+	return gitBlobRawsize(b.gitBlob)
+}
+
+func (b Blob) String() string {
+	return b.ID().String()
+}
+
+// Type returns ObjectBlob.
+func (b Blob) Type() ObjectType {
+	return ObjectBlob
+}
+
+// blobReader reads the raw contents of a blob it holds its own reference to.
+type blobReader struct {
+	blob *gitBlob
+	size int64
+	off  int64
+}
+
+func (r *blobReader) Read(p []byte) (int, error) {
+	if r.blob.ptr == nil {
+		return 0, errors.New("read from closed blob reader")
+	}
+	if r.off >= r.size {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	n := r.size - r.off
+	if n > int64(len(p)) {
+		n = int64(len(p))
+	}
+
+	src := unsafe.Pointer(uintptr(C.git_blob_rawcontent(r.blob.ptr)) + uintptr(r.off))
+	C.memcpy(unsafe.Pointer(&p[0]), src, C.size_t(n))
+	r.off += n
+	return int(n), nil
+}
+
+func (r *blobReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+
+	r.off = offset
+	return offset, nil
+}
+
+func (r *blobReader) Close() error {
+	r.blob.free()
+	return nil
+}
+
+// blobChunkReader feeds the contents of a reader to libgit2 while a blob is
+// created from chunks. The first error of the reader is kept, so that it can
+// be returned in place of the generic libgit2 error.
+//
+// The targeted libgit2 has no git_blob_create_fromstream, which would let Go
+// push the data instead, so git_blob_create_fromchunks pulls it through a
+// callback.
+type blobChunkReader struct {
+	rd  io.Reader
+	buf []byte
+	err error
+}
+
+func createBlobFromReader(repo *gitRepository, rd io.Reader) (OID, error) {
+	cr := &blobChunkReader{rd: rd}
+
+	handle := pointerHandles.track(cr)
+	defer pointerHandles.untrack(handle)
+
+	oid, err := gitBlobCreateFromchunks(repo, handle)
+	if cr.err != nil {
+		return OID{}, cr.err
+	}
+	if err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+//export libgit2BlobChunk
+func libgit2BlobChunk(handle unsafe.Pointer, content *C.char, maxLength C.size_t) C.int {
+	cr := pointerHandles.get(handle).(*blobChunkReader)
+	if uint64(cap(cr.buf)) < uint64(maxLength) {
+		cr.buf = make([]byte, int(maxLength))
+	}
+	buf := cr.buf[:int(maxLength)]
+
+	// libgit2 treats a zero length chunk as the end of the blob, so keep
+	// reading until the reader returns data or fails.
+	for {
+		n, err := cr.rd.Read(buf)
+		if n > 0 {
+			C.memcpy(unsafe.Pointer(content), unsafe.Pointer(&buf[0]), C.size_t(n))
+			return C.int(n)
+		}
+		if err == io.EOF {
+			return 0
+		}
+		if err != nil {
+			cr.err = err
+			return C.GIT_EUSER
+		}
+	}
+}
+
+type gitBlob struct {
+	ptr *C.git_blob
+
+	repo *gitRepository
+}
+
+func (b *gitBlob) init() {
+	runtime.SetFinalizer(b, (*gitBlob).free)
+}
+
+func (b *gitBlob) free() {
+	runtime.SetFinalizer(b, nil)
+	C.git_blob_free(b.ptr)
+	b.ptr = nil
+}
+
+func gitBlobCreateFromchunks(repo *gitRepository, handle unsafe.Pointer) (OID, error) {
+	var oid OID
+
+	if err := unwrapErr(C.libgit2_blob_create_fromchunks(oid.ptr(), repo.ptr, nil, handle)); err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+func gitBlobCreateFrombuffer(repo *gitRepository, data []byte) (OID, error) {
+	var oid OID
+
+	var buf unsafe.Pointer
+	if len(data) > 0 {
+		buf = unsafe.Pointer(&data[0])
+	}
+
+	err := unwrapErr(C.libgit2_blob_create_frombuffer(oid.ptr(), repo.ptr, buf,
+		C.size_t(len(data))))
+	if err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+func gitBlobCreateFromworkdir(repo *gitRepository, path string) (OID, error) {
+	var oid OID
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	if err := unwrapErr(C.libgit2_blob_create_fromworkdir(oid.ptr(), repo.ptr, cpath)); err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+func gitBlobDup(blob *gitBlob) (*gitBlob, error) {
+	b := &gitBlob{repo: blob.repo}
+
+	err := unwrapErr(C.libgit2_object_dup((**C.git_object)(unsafe.Pointer(&b.ptr)),
+		(*C.git_object)(unsafe.Pointer(blob.ptr))))
+	if err != nil {
+		return nil, err
+	}
+	b.init()
+	return b, nil
+}
+
+func gitBlobID(blob *gitBlob) OID {
+	return newOID(C.git_blob_id(blob.ptr))
+}
+
+func gitBlobIsBinary(blob *gitBlob) bool {
+	return int(C.git_blob_is_binary(blob.ptr)) == 1
+}
+
+func gitBlobRawsize(blob *gitBlob) int64 {
+	return int64(C.git_blob_rawsize(blob.ptr))
+}
@@ -0,0 +1,170 @@
+package libgit2
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateBlob(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	data := []byte("hello, world\n")
+	oid, err := repo.CreateBlob(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "4b5fa63702dd96796042e92787f464e28f09f17d", oid.String(); want != got {
+		t.Errorf("want blob %s, got %s", want, got)
+	}
+
+	blob, err := repo.LookupBlob(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blob.Close()
+
+	if want, got := int64(len(data)), blob.Size(); want != got {
+		t.Errorf("want size %d, got %d", want, got)
+	}
+	if blob.IsBinary() {
+		t.Error("want text blob")
+	}
+
+	if got := mustReadBlob(t, blob); !bytes.Equal(data, got) {
+		t.Errorf("want contents %q, got %q", data, got)
+	}
+}
+
+func TestCreateBlobFromReader(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	data := bytes.Repeat([]byte{0, 1, 2, 3, 4, 5, 6, 7}, 1<<17)
+	oid, err := repo.CreateBlobFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := repo.CreateBlob(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.String() != oid.String() {
+		t.Errorf("want blob %s, got %s", want, oid)
+	}
+
+	blob, err := repo.LookupBlob(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blob.Close()
+
+	if !blob.IsBinary() {
+		t.Error("want binary blob")
+	}
+	if got := mustReadBlob(t, blob); !bytes.Equal(data, got) {
+		t.Errorf("want %d bytes of contents, got %d", len(data), len(got))
+	}
+}
+
+func TestCreateBlobFromReaderError(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	errRead := errors.New("read failed")
+	rd := io.MultiReader(bytes.NewReader([]byte("partial")), &errReader{errRead})
+
+	if _, err := repo.CreateBlobFromReader(rd); err != errRead {
+		t.Errorf("want error %v, got %v", errRead, err)
+	}
+}
+
+func TestCreateBlobFromWorkdir(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	name := rndstr()
+	data := []byte(rndstr())
+	if err := ioutil.WriteFile(filepath.Join(repo.Workdir(), name), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oid, err := repo.CreateBlobFromWorkdir(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := repo.CreateBlob(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.String() != oid.String() {
+		t.Errorf("want blob %s, got %s", want, oid)
+	}
+
+	if _, err := repo.CreateBlobFromWorkdir(rndstr()); err == nil {
+		t.Error("want error for missing file")
+	}
+}
+
+func TestBlobReaderAfterClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	data := []byte(rndstr())
+	oid, err := repo.CreateBlob(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blob, err := repo.LookupBlob(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd, err := blob.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob.Close()
+
+	if _, err := blob.Reader(); err != errObjectClosed {
+		t.Errorf("want error %q, got %v", errObjectClosed, err)
+	}
+
+	got, err := ioutil.ReadAll(rd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, got) {
+		t.Errorf("want contents %q, got %q", data, got)
+	}
+
+	if err := rd.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rd.Read(make([]byte, 1)); err == nil {
+		t.Error("want error reading from closed reader")
+	}
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func mustReadBlob(t *testing.T, blob *Blob) []byte {
+	rd, err := blob.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rd.Close()
+
+	data, err := ioutil.ReadAll(rd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
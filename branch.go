@@ -35,8 +35,19 @@ func createBranch(config *branchConfig) (*Branch, error) {
 	return &Branch{ref, branchLocal, config.repo}, nil
 }
 
+// Close releases the branch reference. It may be called more than once.
+func (b Branch) Close() error {
+	b.free()
+	return nil
+}
+
 // Delete an existing branch reference.
 func (b Branch) Delete() error {
+	if err := b.acquire(); err != nil {
+		return err
+	}
+	defer b.repo.release()
+
 	return gitBranchDelete(b.gitReference)
 }
 
@@ -52,6 +63,9 @@ func (b Branch) Move(newName string, options ...BranchOption) (*Branch, error) {
 
 // Name return the name of the given local or remote branch.
 func (b Branch) Name() (string, error) {
+	if b.ptr == nil {
+		return "", errReferenceClosed
+	}
 	return gitBranchName(b.gitReference)
 }
 
@@ -62,11 +76,17 @@ func (b *Branch) Rename(newName string, options ...BranchOption) error {
 		return err
 	}
 
+	b.gitReference.free()
 	b.gitReference = ref
 	return nil
 }
 
 func (b Branch) move(newName string, options []BranchOption) (*gitReference, error) {
+	if err := b.acquire(); err != nil {
+		return nil, err
+	}
+	defer b.repo.release()
+
 	config := &branchConfig{repo: b.repo, name: newName}
 	for _, opt := range options {
 		opt(config)
@@ -79,6 +99,15 @@ func (b Branch) move(newName string, options []BranchOption) (*gitReference, err
 		config.sig.gitSignature, config.logMessage)
 }
 
+// acquire acquires the repository of the branch for the duration of a call,
+// and fails once the branch reference itself is closed.
+func (b Branch) acquire() error {
+	if b.ptr == nil {
+		return errReferenceClosed
+	}
+	return b.repo.acquire()
+}
+
 // BranchWalker is an in-progress walk of branches in a repo.
 type BranchWalker struct {
 	*gitBranchIterator
@@ -117,6 +146,14 @@ func (w *BranchWalker) Cancel() {
 	w.co.Do(w.cancel)
 }
 
+// Close aborts the walk, if in progress, and releases the walker. It may be
+// called more than once.
+func (w *BranchWalker) Close() error {
+	w.Cancel()
+	w.free()
+	return nil
+}
+
 // Err returns error encountered while walking branches.
 func (w *BranchWalker) Err() error {
 	return w.err
@@ -181,6 +218,7 @@ func (i *gitBranchIterator) init() {
 func (i *gitBranchIterator) free() {
 	runtime.SetFinalizer(i, nil)
 	C.git_branch_iterator_free(i.ptr)
+	i.ptr = nil
 }
 
 func (i *gitBranchIterator) next() (*gitReference, branchType, error) {
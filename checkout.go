@@ -0,0 +1,55 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import "unsafe"
+
+type checkoutStrategy uint
+
+const (
+	checkoutForce           checkoutStrategy = C.GIT_CHECKOUT_FORCE
+	checkoutDontUpdateIndex checkoutStrategy = C.GIT_CHECKOUT_DONT_UPDATE_INDEX
+)
+
+// checkoutTreeTo writes the tree of commit into dir and indexPath, leaving
+// the repository's own working directory and index untouched.
+func checkoutTreeTo(repo Repository, commit *Commit, dir, indexPath string) error {
+	tree, err := gitCommitTree(commit.gitCommit)
+	if err != nil {
+		return err
+	}
+
+	idx, err := gitIndexOpen(indexPath)
+	if err != nil {
+		return err
+	}
+	if err := gitIndexReadTree(idx, tree); err != nil {
+		return err
+	}
+	if err := gitIndexWrite(idx); err != nil {
+		return err
+	}
+
+	return gitCheckoutTree(repo.gitRepository, (*C.git_object)(unsafe.Pointer(tree.ptr)),
+		dir, checkoutForce|checkoutDontUpdateIndex)
+}
+
+func gitCheckoutTree(repo *gitRepository, treeish *C.git_object, targetDir string,
+	strategy checkoutStrategy) error {
+
+	opts := &C.git_checkout_options{}
+	err := unwrapErr(C.libgit2_checkout_init_options(opts,
+		C.GIT_CHECKOUT_OPTIONS_VERSION))
+	if err != nil {
+		return err
+	}
+	opts.checkout_strategy = C.uint(strategy)
+
+	if targetDir != "" {
+		opts.target_directory = C.CString(targetDir)
+		defer C.free(unsafe.Pointer(opts.target_directory))
+	}
+
+	return unwrapErr(C.libgit2_checkout_tree(repo.ptr, treeish, opts))
+}
@@ -0,0 +1,171 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+type cloneLocal int
+
+const (
+	cloneLocalAuto    cloneLocal = C.GIT_CLONE_LOCAL_AUTO
+	cloneLocalLinks   cloneLocal = C.GIT_CLONE_LOCAL
+	cloneNoLocal      cloneLocal = C.GIT_CLONE_NO_LOCAL
+	cloneLocalNoLinks cloneLocal = C.GIT_CLONE_LOCAL_NO_LINKS
+)
+
+// TransferProgress holds the stats of an in-progress object transfer.
+type TransferProgress struct {
+	TotalObjects    uint
+	IndexedObjects  uint
+	ReceivedObjects uint
+	LocalObjects    uint
+	TotalDeltas     uint
+	IndexedDeltas   uint
+	ReceivedBytes   uint64
+}
+
+// ProgressReporter receives the transfer stats of a clone on its channel C.
+// The clone never blocks on C: when C is full the oldest pending update is
+// dropped for the newest, so the final stats are always delivered. C is
+// closed when the clone finishes, so a reporter serves a single clone:
+// passing it to another clone fails.
+type ProgressReporter struct {
+	C <-chan TransferProgress
+
+	c  chan TransferProgress
+	co *sync.Once
+
+	mu   sync.Mutex
+	used bool
+}
+
+// NewProgressReporter returns a reporter whose channel buffers up to n
+// updates, and at least one.
+func NewProgressReporter(n int) *ProgressReporter {
+	if n < 1 {
+		n = 1
+	}
+
+	c := make(chan TransferProgress, n)
+	return &ProgressReporter{C: c, c: c, co: &sync.Once{}}
+}
+
+// use claims the reporter for a clone, and fails if it was already claimed.
+func (p *ProgressReporter) use() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.used {
+		return errProgressReporterUsed
+	}
+	p.used = true
+	return nil
+}
+
+func (p *ProgressReporter) close() {
+	p.co.Do(func() { close(p.c) })
+}
+
+// send queues an update without blocking, dropping the oldest queued update
+// if the channel is full.
+func (p *ProgressReporter) send(stats TransferProgress) {
+	for {
+		select {
+		case p.c <- stats:
+			return
+		default:
+		}
+
+		select {
+		case <-p.c:
+		default:
+		}
+	}
+}
+
+// CloneRepository clones the repository at url into dir. The url may be a
+// local path or a file:// URL.
+func CloneRepository(url, dir string, options ...CloneOption) (*Repository, error) {
+	config := &cloneConfig{url: url, dir: dir}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+
+	return cloneRepository(config)
+}
+
+func cloneRepository(config *cloneConfig) (*Repository, error) {
+	if config.progress != nil {
+		defer config.progress.close()
+	}
+
+	opts := &C.git_clone_options{}
+	err := unwrapErr(C.libgit2_clone_init_options(opts,
+		C.GIT_CLONE_OPTIONS_VERSION))
+	if err != nil {
+		return nil, err
+	}
+
+	opts.bare = cbool(config.bare)
+	opts.local = C.git_clone_local_t(config.local)
+
+	if config.checkoutBranch != "" {
+		opts.checkout_branch = C.CString(config.checkoutBranch)
+		defer C.free(unsafe.Pointer(opts.checkout_branch))
+	}
+
+	if config.progress != nil {
+		payload := pointerHandles.track(config.progress)
+		defer pointerHandles.untrack(payload)
+
+		C.libgit2_clone_options_set_transfer_progress(opts, payload)
+	}
+
+	r, err := gitClone(config.url, config.dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Repository{r}, nil
+}
+
+//export libgit2TransferProgress
+func libgit2TransferProgress(stats *C.git_transfer_progress, payload unsafe.Pointer) C.int {
+	p, ok := pointerHandles.get(payload).(*ProgressReporter)
+	if !ok {
+		return 0
+	}
+
+	p.send(TransferProgress{
+		TotalObjects:    uint(stats.total_objects),
+		IndexedObjects:  uint(stats.indexed_objects),
+		ReceivedObjects: uint(stats.received_objects),
+		LocalObjects:    uint(stats.local_objects),
+		TotalDeltas:     uint(stats.total_deltas),
+		IndexedDeltas:   uint(stats.indexed_deltas),
+		ReceivedBytes:   uint64(stats.received_bytes),
+	})
+	return 0
+}
+
+func gitClone(url, localPath string, opts *C.git_clone_options) (*gitRepository, error) {
+	r := new(gitRepository)
+
+	curl := C.CString(url)
+	defer C.free(unsafe.Pointer(curl))
+
+	cpath := C.CString(localPath)
+	defer C.free(unsafe.Pointer(cpath))
+
+	if err := unwrapErr(C.libgit2_clone(&r.ptr, curl, cpath, opts)); err != nil {
+		return nil, err
+	}
+	r.init()
+	return r, nil
+}
@@ -0,0 +1,77 @@
+package libgit2
+
+import "errors"
+
+var (
+	errCloneDepthUnsupported = errors.New("shallow clones are not supported by libgit2")
+	errProgressReporterUsed  = errors.New("progress reporter already used by another clone")
+)
+
+type cloneConfig struct {
+	url, dir string
+
+	bare           bool
+	checkoutBranch string
+	local          cloneLocal
+	depth          int
+
+	progress *ProgressReporter
+}
+
+func (c *cloneConfig) check() error {
+	if c.depth > 0 {
+		return errCloneDepthUnsupported
+	}
+	if c.progress != nil {
+		return c.progress.use()
+	}
+
+	return nil
+}
+
+// CloneOption is an option type for cloning a repository.
+type CloneOption func(*cloneConfig)
+
+// CloneBare creates a bare repository instead of checking out a working
+// directory.
+func CloneBare(c *cloneConfig) { c.bare = true }
+
+// CheckoutBranch checks out the named branch instead of the remote's default
+// branch.
+func CheckoutBranch(name string) CloneOption {
+	return func(c *cloneConfig) {
+		c.checkoutBranch = name
+	}
+}
+
+// Depth limits the history fetched to the given number of commits. libgit2
+// cannot create shallow repositories, so any depth other than zero (the full
+// history) makes the clone fail.
+func Depth(n int) CloneOption {
+	return func(c *cloneConfig) {
+		c.depth = n
+	}
+}
+
+// LocalClone clones a local path by copying the object database files
+// directly instead of using the git-aware transport. If hardlinks is true,
+// the files are hardlinked rather than copied where possible.
+//
+// By default local paths are copied this way, but file:// URLs use the
+// transport.
+func LocalClone(hardlinks bool) CloneOption {
+	return func(c *cloneConfig) {
+		if hardlinks {
+			c.local = cloneLocalLinks
+		} else {
+			c.local = cloneLocalNoLinks
+		}
+	}
+}
+
+// Progress reports transfer stats to p while objects are fetched.
+func Progress(p *ProgressReporter) CloneOption {
+	return func(c *cloneConfig) {
+		c.progress = p
+	}
+}
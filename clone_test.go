@@ -0,0 +1,111 @@
+package libgit2
+
+import "testing"
+
+func TestCloneRepository(t *testing.T) {
+	src := mustInitTestRepo(t)
+	pushd(t, src.Workdir())
+	mustSeedRepoN(t, src, 3)
+	popd(t)
+
+	repo, err := CloneRepository(src.Workdir(), rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.IsBare() {
+		t.Error("got bare repo, want normal")
+	}
+
+	want, err := src.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.target().String() != got.target().String() {
+		t.Errorf("want HEAD %s, got %s", want.target(), got.target())
+	}
+}
+
+func TestCloneRepositoryBare(t *testing.T) {
+	src := mustInitTestRepo(t)
+	pushd(t, src.Workdir())
+	mustSeedRepo(t, src)
+	popd(t)
+
+	repo, err := CloneRepository(src.Workdir(), rndstr(), CloneBare, LocalClone(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.IsBare() {
+		t.Error("got normal repo, want bare")
+	}
+}
+
+func TestCloneRepositoryCheckoutBranch(t *testing.T) {
+	src := mustInitTestRepo(t)
+	pushd(t, src.Workdir())
+	mustSeedRepo(t, src)
+	branch, err := src.CreateBranch(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	popd(t)
+
+	want, err := branch.Name()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := CloneRepository(src.Workdir(), rndstr(), CheckoutBranch(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.LocalBranch(want); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloneRepositoryProgress(t *testing.T) {
+	src := mustInitTestRepo(t)
+	pushd(t, src.Workdir())
+	mustSeedRepoN(t, src, 3)
+	popd(t)
+
+	// nothing reads the updates until the clone is done, which must
+	// neither block it nor lose the final stats
+	p := NewProgressReporter(1)
+	if _, err := CloneRepository("file://"+src.Workdir(), rndstr(), Progress(p)); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := []TransferProgress{}
+	for s := range p.C {
+		stats = append(stats, s)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("want the final transfer progress, got %d updates", len(stats))
+	}
+
+	last := stats[len(stats)-1]
+	if last.TotalObjects == 0 || last.ReceivedObjects != last.TotalObjects {
+		t.Errorf("want all objects received, got %+v", last)
+	}
+
+	_, err := CloneRepository("file://"+src.Workdir(), rndstr(), Progress(p))
+	if err != errProgressReporterUsed {
+		t.Errorf("want error %q, got %v", errProgressReporterUsed, err)
+	}
+}
+
+func TestCloneRepositoryDepth(t *testing.T) {
+	src := mustInitTestRepo(t)
+
+	_, err := CloneRepository(src.Workdir(), rndstr(), Depth(1))
+	if err != errCloneDepthUnsupported {
+		t.Errorf("want error %q, got %v", errCloneDepthUnsupported, err)
+	}
+}
@@ -4,8 +4,10 @@ package libgit2
 import "C"
 
 import (
+	"io/fs"
 	"runtime"
 	"strings"
+	"time"
 	"unsafe"
 )
 
@@ -16,6 +18,9 @@ type Commit struct {
 
 // Author returns the signature of the author of the commit.
 func (c Commit) Author() (*Signature, error) {
+	if c.ptr == nil {
+		return nil, errObjectClosed
+	}
 	sig, err := c.author()
 	if err != nil {
 		return nil, err
@@ -23,18 +28,172 @@ func (c Commit) Author() (*Signature, error) {
 	return &Signature{sig}, nil
 }
 
-// Message is the full message of a commit.
+// Body returns the message of the commit without its subject, the first
+// paragraph, and with surrounding whitespace removed.
+func (c Commit) Body() string {
+	parts := strings.SplitN(c.Message(), "\n\n", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// Close releases the commit. It may be called more than once.
+func (c Commit) Close() error {
+	c.free()
+	return nil
+}
+
+// Committer returns the signature of the committer of the commit.
+func (c Commit) Committer() (*Signature, error) {
+	if c.ptr == nil {
+		return nil, errObjectClosed
+	}
+	sig, err := c.committer()
+	if err != nil {
+		return nil, err
+	}
+	return &Signature{sig}, nil
+}
+
+// FS returns the files of the commit as a fs.FS, backed by the tree of the
+// commit.
+func (c Commit) FS() (fs.FS, error) {
+	tree, err := c.Peel(ObjectTree)
+	if err != nil {
+		return nil, err
+	}
+	return tree.(*Tree), nil
+}
+
+// HeaderField returns the value of a header of the commit, such as gpgsig or
+// mergetag, and whether the header is present. Multi-line values are joined
+// with newlines, without the leading space of continuation lines.
+func (c Commit) HeaderField(name string) (string, bool) {
+	var (
+		value []string
+		found bool
+	)
+	for _, line := range strings.Split(c.RawHeader(), "\n") {
+		if found {
+			if !strings.HasPrefix(line, " ") {
+				break
+			}
+			value = append(value, line[1:])
+			continue
+		}
+		if strings.HasPrefix(line, name+" ") {
+			value = append(value, line[len(name)+1:])
+			found = true
+		}
+	}
+	return strings.Join(value, "\n"), found
+}
+
+// Message is the full message of a commit, with leading newlines removed.
 func (c Commit) Message() string {
+	if c.ptr == nil {
+		return ""
+	}
 	return gitCommitMessage(c.gitCommit)
 }
 
+// MessageEncoding is the encoding of the message of the commit. It is empty
+// if the commit does not record an encoding, in which case the message is
+// UTF-8.
+func (c Commit) MessageEncoding() string {
+	if c.ptr == nil {
+		return ""
+	}
+	return gitCommitMessageEncoding(c.gitCommit)
+}
+
 // ID is the object ID of the commit.
 func (c Commit) ID() OID {
-	return OID{gitCommitID(c.gitCommit)}
+	if c.ptr == nil {
+		return OID{}
+	}
+	return gitCommitID(c.gitCommit)
 }
 
-// Parents are the parent commits of the commit.
+// IsShallowRoot reports whether the commit is at the boundary of a shallow
+// repository, where its parents are not available.
+func (c Commit) IsShallowRoot() (bool, error) {
+	if err := c.acquire(); err != nil {
+		return false, err
+	}
+	defer c.repo.release()
+
+	roots, err := shallowRoots(c.repo)
+	if err != nil {
+		return false, err
+	}
+	return isShallowRoot(roots, c.ID()), nil
+}
+
+// NthGenAncestor returns the ancestor of the commit n generations back,
+// following only first parents. The 0th generation ancestor is the commit
+// itself.
+func (c Commit) NthGenAncestor(n uint) (*Commit, error) {
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	defer c.repo.release()
+
+	cmt, err := gitCommitNthGenAncestor(c.gitCommit, n)
+	if err != nil {
+		return nil, err
+	}
+	return &Commit{cmt}, nil
+}
+
+// ParentCount is the number of parents of the commit.
+func (c Commit) ParentCount() (int, error) {
+	if c.ptr == nil {
+		return 0, errObjectClosed
+	}
+	n, err := gitCommitParentcount(c.gitCommit)
+	return int(n), err
+}
+
+// ParentIDs are the object IDs of the parents of the commit. The parents are
+// not looked up, so the IDs are returned even for a shallow root.
+func (c Commit) ParentIDs() ([]OID, error) {
+	if c.ptr == nil {
+		return nil, errObjectClosed
+	}
+	n, err := gitCommitParentcount(c.gitCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]OID, n)
+	for i := range ids {
+		ids[i] = gitCommitParentID(c.gitCommit, uint(i))
+	}
+	return ids, nil
+}
+
+// Parents are the parent commits of the commit. A commit at the boundary of a
+// shallow repository has no parents, see IsShallowRoot.
 func (c Commit) Parents() ([]*Commit, error) {
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	defer c.repo.release()
+
+	roots, err := shallowRoots(c.repo)
+	if err != nil {
+		return nil, err
+	}
+	return c.parents(roots)
+}
+
+func (c Commit) parents(shallowRoots []OID) ([]*Commit, error) {
+	if isShallowRoot(shallowRoots, c.ID()) {
+		return []*Commit{}, nil
+	}
+
 	n, err := gitCommitParentcount(c.gitCommit)
 	if err != nil {
 		return nil, err
@@ -51,8 +210,36 @@ func (c Commit) Parents() ([]*Commit, error) {
 	return parents, nil
 }
 
+// Peel returns the commit itself for ObjectCommit or ObjectAny, and the tree
+// of the commit for ObjectTree.
+func (c Commit) Peel(t ObjectType) (Object, error) {
+	return peelObject((*C.git_object)(unsafe.Pointer(c.ptr)), c.repo, t)
+}
+
+// RawHeader is the raw header of the commit, holding the tree, parent,
+// author and committer lines as well as any other headers.
+func (c Commit) RawHeader() string {
+	if c.ptr == nil {
+		return ""
+	}
+	return gitCommitRawHeader(c.gitCommit)
+}
+
+// RawMessage is the message of the commit exactly as stored.
+func (c Commit) RawMessage() string {
+	if c.ptr == nil {
+		return ""
+	}
+	return gitCommitMessageRaw(c.gitCommit)
+}
+
 // ShortID returns an abbreviated object ID of the commit.
 func (c Commit) ShortID() (string, error) {
+	if err := c.acquire(); err != nil {
+		return "", err
+	}
+	defer c.repo.release()
+
 	return c.shortID()
 }
 
@@ -65,6 +252,52 @@ func (c Commit) Subject() string {
 	return strings.Split(c.Message(), "\n\n")[0]
 }
 
+// Time is the time of the commit, in the time zone of the committer.
+func (c Commit) Time() time.Time {
+	if c.ptr == nil {
+		return time.Time{}
+	}
+	// git stores minutes, go wants seconds
+	loc := time.FixedZone("", gitCommitTimeOffset(c.gitCommit)*60)
+	return time.Unix(gitCommitTime(c.gitCommit), 0).In(loc)
+}
+
+// Tree returns the tree of the commit.
+func (c Commit) Tree() (*Tree, error) {
+	if err := c.acquire(); err != nil {
+		return nil, err
+	}
+	defer c.repo.release()
+
+	tree, err := gitCommitTree(c.gitCommit)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{tree}, nil
+}
+
+// TreeID is the object ID of the tree of the commit.
+func (c Commit) TreeID() OID {
+	if c.ptr == nil {
+		return OID{}
+	}
+	return gitCommitTreeID(c.gitCommit)
+}
+
+// Type returns ObjectCommit.
+func (c Commit) Type() ObjectType {
+	return ObjectCommit
+}
+
+// acquire acquires the repository of the commit for the duration of a call,
+// and fails once the commit itself is closed.
+func (c Commit) acquire() error {
+	if c.ptr == nil {
+		return errObjectClosed
+	}
+	return c.repo.acquire()
+}
+
 func createCommit(config *commitConfig) (*Commit, error) {
 	gitParents := make([]*gitCommit, len(config.parents))
 	for i, c := range config.parents {
@@ -77,25 +310,43 @@ func createCommit(config *commitConfig) (*Commit, error) {
 	if err != nil {
 		return nil, err
 	}
-	return lookupCommit(config.repo, OID{oid})
+	return lookupCommit(config.repo, oid)
 }
 
 func lookupCommit(repo Repository, oid OID) (*Commit, error) {
-	cmt, err := gitCommitLookup(repo.gitRepository, oid.gitOID)
+	cmt, err := gitCommitLookup(repo.gitRepository, oid)
 	if err != nil {
 		return nil, err
 	}
 	return &Commit{cmt}, nil
 }
 
+func lookupCommits(repo Repository, oids []OID) ([]*Commit, error) {
+	commits := make([]*Commit, len(oids))
+	for i, oid := range oids {
+		c, err := lookupCommit(repo, oid)
+		if err != nil {
+			return nil, err
+		}
+		commits[i] = c
+	}
+	return commits, nil
+}
+
 type gitCommit struct {
 	ptr *C.git_commit
+
+	repo *gitRepository
 }
 
 func (c *gitCommit) author() (*gitSignature, error) {
 	return gitCommitAuthor(c).dup()
 }
 
+func (c *gitCommit) committer() (*gitSignature, error) {
+	return gitCommitCommitter(c).dup()
+}
+
 func (c *gitCommit) init() {
 	runtime.SetFinalizer(c, (*gitCommit).free)
 }
@@ -103,6 +354,7 @@ func (c *gitCommit) init() {
 func (c *gitCommit) free() {
 	runtime.SetFinalizer(c, nil)
 	C.git_commit_free(c.ptr)
+	c.ptr = nil
 }
 
 func (c *gitCommit) shortID() (string, error) {
@@ -120,11 +372,15 @@ func gitCommitAuthor(commit *gitCommit) *gitSignature {
 	return &gitSignature{ptr: C.git_commit_author(commit.ptr)}
 }
 
+func gitCommitCommitter(commit *gitCommit) *gitSignature {
+	return &gitSignature{ptr: C.git_commit_committer(commit.ptr)}
+}
+
 func gitCommitCreate(repo *gitRepository, updateRef string, author,
 	committer *gitSignature, messageEncoding, message string, tree *gitTree,
-	parents []*gitCommit) (*gitOID, error) {
+	parents []*gitCommit) (OID, error) {
 
-	oid := &gitOID{ptr: &C.git_oid{}}
+	var oid OID
 
 	var cref *C.char
 	if updateRef != "" {
@@ -150,15 +406,19 @@ func gitCommitCreate(repo *gitRepository, updateRef string, author,
 		cparents = &ary[0]
 	}
 
-	return oid, unwrapErr(C.libgit2_commit_create(oid.ptr, repo.ptr, cref,
+	err := unwrapErr(C.libgit2_commit_create(oid.ptr(), repo.ptr, cref,
 		author.ptr, committer.ptr, cenc, cmsg, tree.ptr, C.size_t(len(parents)),
 		cparents))
+	if err != nil {
+		return OID{}, err
+	}
+	return oid, nil
 }
 
-func gitCommitLookup(repo *gitRepository, oid *gitOID) (*gitCommit, error) {
-	c := new(gitCommit)
+func gitCommitLookup(repo *gitRepository, oid OID) (*gitCommit, error) {
+	c := &gitCommit{repo: repo}
 
-	err := unwrapErr(C.libgit2_commit_lookup(&c.ptr, repo.ptr, oid.ptr))
+	err := unwrapErr(C.libgit2_commit_lookup(&c.ptr, repo.ptr, oid.ptr()))
 	if err != nil {
 		return nil, err
 	}
@@ -166,20 +426,75 @@ func gitCommitLookup(repo *gitRepository, oid *gitOID) (*gitCommit, error) {
 	return c, nil
 }
 
-func gitCommitID(commit *gitCommit) *gitOID {
-	return &gitOID{C.git_commit_id(commit.ptr)}
+func gitCommitID(commit *gitCommit) OID {
+	return newOID(C.git_commit_id(commit.ptr))
 }
 
 func gitCommitMessage(commit *gitCommit) string {
 	return C.GoString(C.git_commit_message(commit.ptr))
 }
 
+func gitCommitMessageEncoding(commit *gitCommit) string {
+	return C.GoString(C.git_commit_message_encoding(commit.ptr))
+}
+
+func gitCommitMessageRaw(commit *gitCommit) string {
+	return C.GoString(C.git_commit_message_raw(commit.ptr))
+}
+
+func gitCommitNthGenAncestor(commit *gitCommit, n uint) (*gitCommit, error) {
+	c := &gitCommit{repo: commit.repo}
+
+	err := unwrapErr(C.libgit2_commit_nth_gen_ancestor(&c.ptr, commit.ptr, C.uint(n)))
+	if err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+func gitCommitRawHeader(commit *gitCommit) string {
+	return C.GoString(C.git_commit_raw_header(commit.ptr))
+}
+
+func gitCommitTime(commit *gitCommit) int64 {
+	return int64(C.git_commit_time(commit.ptr))
+}
+
+func gitCommitTimeOffset(commit *gitCommit) int {
+	return int(C.git_commit_time_offset(commit.ptr))
+}
+
+func gitCommitTree(commit *gitCommit) (*gitTree, error) {
+	t := &gitTree{repo: commit.repo}
+
+	if err := unwrapErr(C.libgit2_commit_tree(&t.ptr, commit.ptr)); err != nil {
+		return nil, err
+	}
+	t.init()
+	return t, nil
+}
+
 func gitCommitParent(commit *gitCommit, n uint) (*gitCommit, error) {
-	c := new(gitCommit)
-	return c, unwrapErr(C.libgit2_commit_parent(&c.ptr, commit.ptr, C.uint(n)))
+	c := &gitCommit{repo: commit.repo}
+
+	err := unwrapErr(C.libgit2_commit_parent(&c.ptr, commit.ptr, C.uint(n)))
+	if err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+func gitCommitParentID(commit *gitCommit, n uint) OID {
+	return newOID(C.git_commit_parent_id(commit.ptr, C.uint(n)))
 }
 
 func gitCommitParentcount(commit *gitCommit) (uint, error) {
 	res := C.libgit2_commit_parentcount(commit.ptr)
 	return uint(res.code), unwrapErr(res)
 }
+
+func gitCommitTreeID(commit *gitCommit) OID {
+	return newOID(C.git_commit_tree_id(commit.ptr))
+}
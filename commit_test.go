@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCreateEmptyCommit(t *testing.T) {
@@ -135,6 +136,212 @@ func TestCommitAuthor(t *testing.T) {
 	}
 }
 
+func TestCommitBody(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	tests := []struct {
+		body, message string
+	}{
+		{body: "", message: "a simple subject\n"},
+		{body: "the remaining message", message: "a subject\n\nthe remaining message\n"},
+		{body: "first\n\nsecond", message: "a subject\n\n\nfirst\n\nsecond\n\n"},
+	}
+
+	for _, test := range tests {
+		commit, err := repo.Commit(AllowEmpty, Message(test.message), CleanupMessage(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want, got := test.body, commit.Body(); want != got {
+			t.Errorf("want commit body %q, got %q", want, got)
+		}
+	}
+}
+
+func TestCommitClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustWriteTestTree(t, repo, map[string]string{"file": rndstr()})
+
+	commit, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := commit.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := commit.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if msg := commit.Message(); msg != "" {
+		t.Errorf("want no message from a closed commit, got %q", msg)
+	}
+	if _, err := commit.Author(); err != errObjectClosed {
+		t.Errorf("want error %q, got %v", errObjectClosed, err)
+	}
+	if _, err := commit.Parents(); err != errObjectClosed {
+		t.Errorf("want error %q, got %v", errObjectClosed, err)
+	}
+	if _, err := commit.Peel(ObjectTree); err != errObjectClosed {
+		t.Errorf("want error %q, got %v", errObjectClosed, err)
+	}
+
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if entries := tree.Entries(); len(entries) != 0 {
+		t.Errorf("want no entries from a closed tree, got %v", entries)
+	}
+	if _, err := tree.EntryByPath("file"); err != errObjectClosed {
+		t.Errorf("want error %q, got %v", errObjectClosed, err)
+	}
+}
+
+func TestCommitCommitter(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	want, err := repo.DefaultSignature()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := repo.Commit(Message("testing commit committer"), AllowEmpty)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := commit.Committer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Name != got.Name || want.Email != got.Email {
+		t.Errorf("want committer %s <%s>, got %s <%s>", want.Name, want.Email, got.Name, got.Email)
+	}
+	if !got.When.Equal(commit.Time()) {
+		t.Errorf("want commit time %q, got %q", got.When, commit.Time())
+	}
+}
+
+func TestCommitHeaders(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	odb, err := repo.ODB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer odb.Close()
+
+	tree, err := odb.Write(ObjectTree, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := "tree " + tree.String() + "\n" +
+		"author A U Thor <author@example.com> 1500000000 +0200\n" +
+		"committer C O Mitter <committer@example.com> 1500000060 -0130\n" +
+		"encoding ISO-8859-1\n" +
+		"gpgsig -----BEGIN PGP SIGNATURE-----\n" +
+		" \n" +
+		" iQEcBAABAgAGBQJZ\n" +
+		" -----END PGP SIGNATURE-----\n"
+	message := "\nsubject\n\nbody\n"
+
+	oid, err := odb.Write(ObjectCommit, []byte(header+"\n"+message))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := repo.LookupCommit(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := header, commit.RawHeader(); want != got {
+		t.Errorf("want raw header %q, got %q", want, got)
+	}
+	if want, got := message, commit.RawMessage(); want != got {
+		t.Errorf("want raw message %q, got %q", want, got)
+	}
+	if want, got := "subject\n\nbody\n", commit.Message(); want != got {
+		t.Errorf("want message %q, got %q", want, got)
+	}
+	if want, got := "ISO-8859-1", commit.MessageEncoding(); want != got {
+		t.Errorf("want message encoding %q, got %q", want, got)
+	}
+	if want, got := tree, commit.TreeID(); want != got {
+		t.Errorf("want tree %s, got %s", want, got)
+	}
+
+	fields := map[string]string{
+		"tree":     tree.String(),
+		"encoding": "ISO-8859-1",
+		"gpgsig":   "-----BEGIN PGP SIGNATURE-----\n\niQEcBAABAgAGBQJZ\n-----END PGP SIGNATURE-----",
+	}
+	for name, want := range fields {
+		got, ok := commit.HeaderField(name)
+		if !ok {
+			t.Errorf("want header %s", name)
+		}
+		if want != got {
+			t.Errorf("want header %s %q, got %q", name, want, got)
+		}
+	}
+	if _, ok := commit.HeaderField("mergetag"); ok {
+		t.Error("want no mergetag header")
+	}
+
+	when := commit.Time()
+	if want := time.Unix(1500000060, 0); !want.Equal(when) {
+		t.Errorf("want commit time %s, got %s", want, when)
+	}
+	if _, offset := when.Zone(); offset != -90*60 {
+		t.Errorf("want commit time zone offset %d, got %d", -90*60, offset)
+	}
+}
+
+func TestCommitNthGenAncestor(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 3)
+
+	walk, err := repo.Walk(Sorting(SortTopological))
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for n, want := range history {
+		got, err := history[0].NthGenAncestor(uint(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want.ID() != got.ID() {
+			t.Errorf("want ancestor %d %s, got %s", n, want, got)
+		}
+	}
+
+	if _, err := history[0].NthGenAncestor(uint(len(history))); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
 func TestCommitParents(t *testing.T) {
 	repo := mustInitTestRepo(t)
 	pushd(t, repo.Workdir())
@@ -161,6 +368,43 @@ func TestCommitParents(t *testing.T) {
 	if !reflect.DeepEqual(want, got) {
 		t.Errorf("want commit parents %v, got %v", want, got)
 	}
+
+	ids, err := commit.ParentIDs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []OID{parent.ID()}, ids; !reflect.DeepEqual(want, got) {
+		t.Errorf("want commit parent ids %v, got %v", want, got)
+	}
+	if n, err := parent.ParentCount(); err != nil || n != 0 {
+		t.Errorf("want no parents, got %d (%v)", n, err)
+	}
+}
+
+func TestCommitTree(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	want := mustWriteTestTree(t, repo, map[string]string{"file": rndstr()})
+
+	commit, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := commit.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer got.Close()
+
+	if want.ID() != got.ID() {
+		t.Errorf("want tree %s, got %s", want.ID(), got.ID())
+	}
+	if want.ID() != commit.TreeID() {
+		t.Errorf("want tree id %s, got %s", want.ID(), commit.TreeID())
+	}
 }
 
 func TestCommitShortID(t *testing.T) {
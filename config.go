@@ -0,0 +1,613 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// errConfigClosed is returned when a config is used after it is closed.
+var errConfigClosed = errors.New("config is closed")
+
+// ConfigLevel is the priority of a config file. Values set in higher levels
+// override the values set in lower levels.
+type ConfigLevel int
+
+const (
+	// ConfigLevelSystem is the system-wide config file (/etc/gitconfig).
+	ConfigLevelSystem ConfigLevel = C.GIT_CONFIG_LEVEL_SYSTEM
+	// ConfigLevelXDG is the XDG compatible config file
+	// ($XDG_CONFIG_HOME/git/config).
+	ConfigLevelXDG ConfigLevel = C.GIT_CONFIG_LEVEL_XDG
+	// ConfigLevelGlobal is the user specific config file (~/.gitconfig).
+	ConfigLevelGlobal ConfigLevel = C.GIT_CONFIG_LEVEL_GLOBAL
+	// ConfigLevelLocal is the repository specific config file
+	// ($GIT_DIR/config).
+	ConfigLevelLocal ConfigLevel = C.GIT_CONFIG_LEVEL_LOCAL
+	// ConfigLevelWorktree is the working tree specific config file
+	// ($GIT_DIR/config.worktree), only loaded when the repository sets
+	// extensions.worktreeConfig. libgit2 has no dedicated level for it, so it
+	// takes the application level, which has the same precedence.
+	ConfigLevelWorktree ConfigLevel = C.GIT_CONFIG_LEVEL_APP
+	// ConfigLevelHighest is the highest level available in a config.
+	ConfigLevelHighest ConfigLevel = C.GIT_CONFIG_HIGHEST_LEVEL
+)
+
+// Config is a set of config files, ordered by level.
+type Config struct {
+	*gitConfig
+}
+
+// DefaultConfig opens the global, XDG and system config files.
+func DefaultConfig() (*Config, error) {
+	cfg, err := gitConfigOpenDefault()
+	if err != nil {
+		return nil, err
+	}
+	return &Config{cfg}, nil
+}
+
+// OpenConfig opens a single config file, such as a .gitmodules file, for
+// reading and editing. The file is created on the first write if it does not
+// exist.
+func OpenConfig(path string) (*Config, error) {
+	cfg, err := gitConfigOpenOndisk(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{cfg}, nil
+}
+
+func repositoryConfig(repo Repository) (*Config, error) {
+	cfg, err := gitRepositoryConfig(repo.gitRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := cfg.bool("extensions.worktreeConfig")
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	if ok {
+		path := filepath.Join(repo.Path(), "config.worktree")
+		if _, err := os.Stat(path); err == nil {
+			err = gitConfigAddFileOndisk(cfg, path, ConfigLevelWorktree, true)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Config{cfg}, nil
+}
+
+// Close releases the config and the files it holds open. It may be called
+// more than once.
+func (c Config) Close() error {
+	c.free()
+	return nil
+}
+
+// Delete removes a variable from the highest level config file that has it.
+func (c Config) Delete(name string) error {
+	if c.ptr == nil {
+		return errConfigClosed
+	}
+	return gitConfigDeleteEntry(c.gitConfig, name)
+}
+
+// DeleteMultivar removes the values of a multivar that match regexp.
+func (c Config) DeleteMultivar(name, regexp string) error {
+	if c.ptr == nil {
+		return errConfigClosed
+	}
+	return gitConfigDeleteMultivar(c.gitConfig, name, regexp)
+}
+
+// GetBool returns the value of a boolean config variable. Values such as
+// "yes", "on" and "1" are all true.
+func (c Config) GetBool(name string) (bool, error) {
+	if c.ptr == nil {
+		return false, errConfigClosed
+	}
+	return c.bool(name)
+}
+
+// GetInt64 returns the value of an integer config variable. Suffixes such as
+// "k", "m" and "g" are expanded.
+func (c Config) GetInt64(name string) (int64, error) {
+	if c.ptr == nil {
+		return 0, errConfigClosed
+	}
+	return gitConfigGetInt64(c.gitConfig, name)
+}
+
+// GetMultivar returns all the values of a multivar that match regexp. An
+// empty regexp matches every value.
+func (c Config) GetMultivar(name, regexp string) ([]string, error) {
+	if c.ptr == nil {
+		return nil, errConfigClosed
+	}
+	iter, err := gitConfigMultivarIteratorNew(c.gitConfig, name, regexp)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.free()
+
+	values := []string{}
+	for {
+		entry, err := iter.next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			return values, nil
+		}
+		values = append(values, entry.Value)
+	}
+}
+
+// GetString returns the value of a config variable.
+func (c Config) GetString(name string) (string, error) {
+	if c.ptr == nil {
+		return "", errConfigClosed
+	}
+	entry, err := gitConfigGetEntry(c.gitConfig, name)
+	if err != nil {
+		return "", err
+	}
+	return entry.Value, nil
+}
+
+// Level returns a config holding only the config file at the given level.
+func (c Config) Level(level ConfigLevel) (*Config, error) {
+	if c.ptr == nil {
+		return nil, errConfigClosed
+	}
+	cfg, err := gitConfigOpenLevel(c.gitConfig, level)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{cfg}, nil
+}
+
+// SetBool sets a boolean config variable in the highest level config file.
+func (c Config) SetBool(name string, value bool) error {
+	if c.ptr == nil {
+		return errConfigClosed
+	}
+	return gitConfigSetBool(c.gitConfig, name, value)
+}
+
+// SetInt64 sets an integer config variable in the highest level config file.
+func (c Config) SetInt64(name string, value int64) error {
+	if c.ptr == nil {
+		return errConfigClosed
+	}
+	return gitConfigSetInt64(c.gitConfig, name, value)
+}
+
+// SetMultivar replaces the values of a multivar that match regexp with value.
+// If no value matches, value is added to the multivar.
+func (c Config) SetMultivar(name, regexp, value string) error {
+	if c.ptr == nil {
+		return errConfigClosed
+	}
+	return gitConfigSetMultivar(c.gitConfig, name, regexp, value)
+}
+
+// SetString sets a config variable in the highest level config file.
+func (c Config) SetString(name, value string) error {
+	if c.ptr == nil {
+		return errConfigClosed
+	}
+	return gitConfigSetString(c.gitConfig, name, value)
+}
+
+// Snapshot returns a read-only copy of the config that does not change when
+// the underlying files are modified.
+func (c Config) Snapshot() (*Config, error) {
+	if c.ptr == nil {
+		return nil, errConfigClosed
+	}
+	cfg, err := gitConfigSnapshot(c.gitConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{cfg}, nil
+}
+
+// Walk returns a walker over the config variables with names matching the
+// glob pattern, such as "core.*". A * matches any run of characters,
+// including dots, and a ? any single character. Section and variable names
+// are matched in lower case, as git stores them. An empty pattern matches
+// every variable.
+func (c Config) Walk(pattern string) (*ConfigWalker, error) {
+	if c.ptr == nil {
+		return nil, errConfigClosed
+	}
+	return newConfigWalker(c, configGlobRegexp(pattern))
+}
+
+// ConfigEntry is a single config variable.
+type ConfigEntry struct {
+	// Name is the full name of the variable, such as "user.name".
+	Name string
+	// Value is the unparsed value of the variable.
+	Value string
+	// Level is the level of the file the variable was read from.
+	Level ConfigLevel
+}
+
+// ConfigWalker is an in-progress walk of the variables in a config.
+type ConfigWalker struct {
+	*gitConfigIterator
+
+	C <-chan *ConfigEntry
+
+	err error
+
+	co *sync.Once
+	cc chan struct{}
+}
+
+// configGlobRegexp translates a Walk glob into the anchored regular
+// expression libgit2 matches variable names with.
+func configGlobRegexp(glob string) string {
+	if glob == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+func newConfigWalker(c Config, regexp string) (*ConfigWalker, error) {
+	iter, err := gitConfigIteratorGlobNew(c.gitConfig, regexp)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *ConfigEntry)
+	w := &ConfigWalker{
+		gitConfigIterator: iter,
+		C:                 ch,
+		co:                &sync.Once{},
+		cc:                make(chan struct{}),
+	}
+
+	go w.run(ch)
+	return w, nil
+}
+
+// Cancel aborts an in-progress walk and drains the entry channel C.
+func (w *ConfigWalker) Cancel() {
+	w.co.Do(w.cancel)
+}
+
+// Close aborts the walk, if in progress, and releases the walker. It may be
+// called more than once.
+func (w *ConfigWalker) Close() error {
+	w.Cancel()
+	w.free()
+	return nil
+}
+
+// Err returns error encountered while walking config variables.
+func (w *ConfigWalker) Err() error {
+	return w.err
+}
+
+// Slice returns a slice holding the config entries and any error encountered
+// while walking the config.
+func (w *ConfigWalker) Slice() ([]*ConfigEntry, error) {
+	s := []*ConfigEntry{}
+	for e := range w.C {
+		s = append(s, e)
+	}
+	return s, w.Err()
+}
+
+func (w *ConfigWalker) cancel() {
+	close(w.cc)
+	for range w.C {
+	}
+}
+
+func (w *ConfigWalker) run(c chan<- *ConfigEntry) {
+	defer close(c)
+
+	for {
+		entry, err := w.gitConfigIterator.next()
+		if err != nil {
+			w.err = err
+			return
+		}
+		if entry == nil {
+			return
+		}
+
+		select {
+		case c <- entry:
+		case <-w.cc:
+			return
+		}
+	}
+}
+
+type gitConfig struct {
+	ptr *C.git_config
+}
+
+func (c *gitConfig) bool(name string) (bool, error) {
+	return gitConfigGetBool(c, name)
+}
+
+func (c *gitConfig) init() {
+	runtime.SetFinalizer(c, (*gitConfig).free)
+}
+
+func (c *gitConfig) free() {
+	runtime.SetFinalizer(c, nil)
+	C.git_config_free(c.ptr)
+	c.ptr = nil
+}
+
+type gitConfigIterator struct {
+	ptr *C.git_config_iterator
+}
+
+func (i *gitConfigIterator) init() {
+	runtime.SetFinalizer(i, (*gitConfigIterator).free)
+}
+
+func (i *gitConfigIterator) free() {
+	runtime.SetFinalizer(i, nil)
+	C.git_config_iterator_free(i.ptr)
+	i.ptr = nil
+}
+
+func (i *gitConfigIterator) next() (*ConfigEntry, error) {
+	var ptr *C.git_config_entry
+
+	if err := unwrapErr(C.libgit2_config_next(&ptr, i.ptr)); err != nil {
+		return nil, err
+	}
+	if ptr == nil {
+		return nil, nil
+	}
+	return newConfigEntry(ptr), nil
+}
+
+func newConfigEntry(ptr *C.git_config_entry) *ConfigEntry {
+	return &ConfigEntry{
+		Name:  C.GoString(ptr.name),
+		Value: C.GoString(ptr.value),
+		Level: ConfigLevel(ptr.level),
+	}
+}
+
+func gitConfigAddFileOndisk(cfg *gitConfig, path string, level ConfigLevel,
+	force bool) error {
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	return unwrapErr(C.libgit2_config_add_file_ondisk(cfg.ptr, cpath,
+		C.git_config_level_t(level), cbool(force)))
+}
+
+func gitConfigDeleteEntry(cfg *gitConfig, name string) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_config_delete_entry(cfg.ptr, cname))
+}
+
+func gitConfigDeleteMultivar(cfg *gitConfig, name, regexp string) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	cregexp := C.CString(regexp)
+	defer C.free(unsafe.Pointer(cregexp))
+
+	return unwrapErr(C.libgit2_config_delete_multivar(cfg.ptr, cname, cregexp))
+}
+
+func gitConfigGetBool(cfg *gitConfig, name string) (bool, error) {
+	var out C.int
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	if err := unwrapErr(C.libgit2_config_get_bool(&out, cfg.ptr, cname)); err != nil {
+		return false, err
+	}
+	return out != 0, nil
+}
+
+// gitConfigGetEntry copies the entry of the named variable. The targeted
+// libgit2 hands out a const entry owned by the config and has no
+// git_config_entry_free, so there is nothing to release here; the strings are
+// copied before the config can be changed or freed.
+func gitConfigGetEntry(cfg *gitConfig, name string) (*ConfigEntry, error) {
+	var ptr *C.git_config_entry
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	if err := unwrapErr(C.libgit2_config_get_entry(&ptr, cfg.ptr, cname)); err != nil {
+		return nil, err
+	}
+	return newConfigEntry(ptr), nil
+}
+
+func gitConfigGetInt64(cfg *gitConfig, name string) (int64, error) {
+	var out C.int64_t
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	if err := unwrapErr(C.libgit2_config_get_int64(&out, cfg.ptr, cname)); err != nil {
+		return 0, err
+	}
+	return int64(out), nil
+}
+
+func gitConfigIteratorGlobNew(cfg *gitConfig, regexp string) (*gitConfigIterator, error) {
+	var ptr *C.git_config_iterator
+
+	var cregexp *C.char
+	if regexp != "" {
+		cregexp = C.CString(regexp)
+		defer C.free(unsafe.Pointer(cregexp))
+	}
+
+	err := unwrapErr(C.libgit2_config_iterator_glob_new(&ptr, cfg.ptr, cregexp))
+	if err != nil {
+		return nil, err
+	}
+
+	i := &gitConfigIterator{ptr}
+	i.init()
+	return i, nil
+}
+
+func gitConfigMultivarIteratorNew(cfg *gitConfig, name,
+	regexp string) (*gitConfigIterator, error) {
+
+	var ptr *C.git_config_iterator
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	var cregexp *C.char
+	if regexp != "" {
+		cregexp = C.CString(regexp)
+		defer C.free(unsafe.Pointer(cregexp))
+	}
+
+	err := unwrapErr(C.libgit2_config_multivar_iterator_new(&ptr, cfg.ptr, cname,
+		cregexp))
+	if err != nil {
+		return nil, err
+	}
+
+	i := &gitConfigIterator{ptr}
+	i.init()
+	return i, nil
+}
+
+func gitConfigOpenDefault() (*gitConfig, error) {
+	c := new(gitConfig)
+
+	if err := unwrapErr(C.libgit2_config_open_default(&c.ptr)); err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+func gitConfigOpenLevel(parent *gitConfig, level ConfigLevel) (*gitConfig, error) {
+	c := new(gitConfig)
+
+	err := unwrapErr(C.libgit2_config_open_level(&c.ptr, parent.ptr,
+		C.git_config_level_t(level)))
+	if err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+func gitConfigOpenOndisk(path string) (*gitConfig, error) {
+	c := new(gitConfig)
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	if err := unwrapErr(C.libgit2_config_open_ondisk(&c.ptr, cpath)); err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+func gitConfigSetBool(cfg *gitConfig, name string, value bool) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_config_set_bool(cfg.ptr, cname, cbool(value)))
+}
+
+func gitConfigSetInt64(cfg *gitConfig, name string, value int64) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_config_set_int64(cfg.ptr, cname,
+		C.int64_t(value)))
+}
+
+func gitConfigSetMultivar(cfg *gitConfig, name, regexp, value string) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	cregexp := C.CString(regexp)
+	defer C.free(unsafe.Pointer(cregexp))
+
+	cvalue := C.CString(value)
+	defer C.free(unsafe.Pointer(cvalue))
+
+	return unwrapErr(C.libgit2_config_set_multivar(cfg.ptr, cname, cregexp,
+		cvalue))
+}
+
+func gitConfigSetString(cfg *gitConfig, name, value string) error {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	cvalue := C.CString(value)
+	defer C.free(unsafe.Pointer(cvalue))
+
+	return unwrapErr(C.libgit2_config_set_string(cfg.ptr, cname, cvalue))
+}
+
+func gitConfigSnapshot(cfg *gitConfig) (*gitConfig, error) {
+	c := new(gitConfig)
+
+	if err := unwrapErr(C.libgit2_config_snapshot(&c.ptr, cfg.ptr)); err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
+
+func gitRepositoryConfig(repo *gitRepository) (*gitConfig, error) {
+	c := new(gitConfig)
+
+	if err := unwrapErr(C.libgit2_repository_config(&c.ptr, repo.ptr)); err != nil {
+		return nil, err
+	}
+	c.init()
+	return c, nil
+}
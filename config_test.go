@@ -0,0 +1,283 @@
+package libgit2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigString(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := cfg.GetString("user.name"); err != nil {
+		t.Fatal(err)
+	} else if got != "Default" {
+		t.Errorf("want user.name %q, got %q", "Default", got)
+	}
+
+	want := rndstr()
+	if err := cfg.SetString("test.string", want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cfg.GetString("test.string")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != got {
+		t.Errorf("want test.string %q, got %q", want, got)
+	}
+
+	if err := cfg.Delete("test.string"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.GetString("test.string"); !isNotFound(err) {
+		t.Errorf("want errNotFound error, got %v", err)
+	}
+}
+
+func TestConfigTyped(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetBool("test.bool", true); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cfg.GetBool("test.bool"); err != nil {
+		t.Fatal(err)
+	} else if !got {
+		t.Error("want test.bool true, got false")
+	}
+
+	if err := cfg.SetString("test.int", "2k"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cfg.GetInt64("test.int"); err != nil {
+		t.Fatal(err)
+	} else if got != 2048 {
+		t.Errorf("want test.int %d, got %d", 2048, got)
+	}
+}
+
+func TestConfigMultivar(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"one", "two", "three"}
+	for _, v := range want {
+		if err := cfg.SetMultivar("test.multi", "^$", v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := cfg.GetMultivar("test.multi", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want test.multi %v, got %v", want, got)
+	}
+
+	if err := cfg.DeleteMultivar("test.multi", "^t"); err != nil {
+		t.Fatal(err)
+	}
+
+	want = []string{"one"}
+	if got, err = cfg.GetMultivar("test.multi", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want test.multi %v, got %v", want, got)
+	}
+}
+
+func TestConfigWalk(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"walk.a": rndstr(), "walk.b": rndstr()}
+	for name, value := range want {
+		if err := cfg.SetString(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cfg.SetString("walks.c", rndstr()); err != nil {
+		t.Fatal(err)
+	}
+
+	walker, err := cfg.Walk("walk.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := walker.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, entry := range entries {
+		if entry.Level != ConfigLevelLocal {
+			t.Errorf("want %q level %d, got %d", entry.Name, ConfigLevelLocal, entry.Level)
+		}
+		got[entry.Name] = entry.Value
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want config entries %v, got %v", want, got)
+	}
+}
+
+func TestConfigClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cfg.GetString("user.name"); err != errConfigClosed {
+		t.Errorf("want error %q, got %v", errConfigClosed, err)
+	}
+	if err := cfg.SetString("test.string", rndstr()); err != errConfigClosed {
+		t.Errorf("want error %q, got %v", errConfigClosed, err)
+	}
+	if _, err := cfg.Walk(""); err != errConfigClosed {
+		t.Errorf("want error %q, got %v", errConfigClosed, err)
+	}
+}
+
+func TestConfigLevel(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local, err := cfg.Level(ConfigLevelLocal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.GetString("user.name"); !isNotFound(err) {
+		t.Errorf("want errNotFound error, got %v", err)
+	}
+
+	global, err := cfg.Level(ConfigLevelGlobal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := global.GetString("user.name"); err != nil {
+		t.Fatal(err)
+	} else if got != "Default" {
+		t.Errorf("want user.name %q, got %q", "Default", got)
+	}
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := rndstr()
+	if err := cfg.SetString("test.snapshot", want); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := cfg.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetString("test.snapshot", rndstr()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := snap.GetString("test.snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != got {
+		t.Errorf("want snapshot value %q, got %q", want, got)
+	}
+}
+
+func TestConfigWorktreeLevel(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetBool("extensions.worktreeConfig", true); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(repo.Path(), "config.worktree")
+	data := []byte("[user]\n\tname = Worktree\n")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, err = repo.Config(); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cfg.GetString("user.name"); err != nil {
+		t.Fatal(err)
+	} else if got != "Worktree" {
+		t.Errorf("want user.name %q, got %q", "Worktree", got)
+	}
+}
+
+func TestOpenConfig(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	path := filepath.Join(repo.Workdir(), ".gitmodules")
+	cfg, err := OpenConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "https://example.com/sub.git"
+	if err := cfg.SetString("submodule.sub.url", want); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, err = OpenConfig(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cfg.GetString("submodule.sub.url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != got {
+		t.Errorf("want submodule url %q, got %q", want, got)
+	}
+}
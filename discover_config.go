@@ -0,0 +1,45 @@
+package libgit2
+
+import "path/filepath"
+
+type discoverConfig struct {
+	start       string
+	acrossFS    bool
+	ceilingDirs []string
+}
+
+func (c *discoverConfig) check() error {
+	if c.start == "" {
+		c.start = "."
+	}
+
+	// libgit2 only matches ceiling directories against absolute paths.
+	for i, dir := range c.ceilingDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return err
+		}
+		c.ceilingDirs[i] = abs
+	}
+
+	return nil
+}
+
+// DiscoverOption is an option type for repository discovery.
+type DiscoverOption func(*discoverConfig)
+
+// AcrossFS allows the search to continue past filesystem boundaries. By
+// default the search stops at the first directory on a different device.
+func AcrossFS() DiscoverOption {
+	return func(c *discoverConfig) {
+		c.acrossFS = true
+	}
+}
+
+// CeilingDirs stops the search before it reaches any of the given
+// directories.
+func CeilingDirs(dirs ...string) DiscoverOption {
+	return func(c *discoverConfig) {
+		c.ceilingDirs = append(c.ceilingDirs, dirs...)
+	}
+}
@@ -3,6 +3,56 @@ package libgit2
 //#include <git2.h>
 import "C"
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// Errors returned by Go implemented backends to signal a specific condition
+// to libgit2.
+var (
+	// ErrNotFound means the requested object or reference does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrExists means a reference already exists and cannot be overwritten.
+	ErrExists = errors.New("already exists")
+	// ErrModified means the current value of a reference does not match the
+	// expected old value.
+	ErrModified = errors.New("reference value does not match expected")
+	// ErrLocked means a reference is locked by another writer.
+	ErrLocked = errors.New("locked")
+)
+
+// ErrClosed is returned when using a repository after it has been closed.
+var ErrClosed = errors.New("repository is closed")
+
+// AmbiguousOIDError is returned when an abbreviated object ID matches more
+// than one object.
+type AmbiguousOIDError struct {
+	// Prefix is the abbreviated object ID.
+	Prefix string
+	// Candidates are the IDs of the objects matching the prefix, sorted.
+	// At most 16 of the matching objects are listed.
+	Candidates []OID
+}
+
+func (e *AmbiguousOIDError) Error() string {
+	ids := make([]string, len(e.Candidates))
+	for i, oid := range e.Candidates {
+		ids[i] = oid.String()
+	}
+	return fmt.Sprintf("ambiguous oid prefix %q: candidates are %s", e.Prefix,
+		strings.Join(ids, ", "))
+}
+
+var backendErrorCodes = map[error]C.int{
+	ErrNotFound: C.GIT_ENOTFOUND,
+	ErrExists:   C.GIT_EEXISTS,
+	ErrModified: C.GIT_EMODIFIED,
+	ErrLocked:   C.GIT_ELOCKED,
+}
+
 //TODO(benburkert): properly attribute this to git2go
 
 type errorClass int
@@ -88,3 +138,26 @@ type gitError struct {
 func (e gitError) Error() string {
 	return e.message
 }
+
+// backendError reports err to libgit2 from a backend callback.
+func backendError(class errorClass, err error) C.int {
+	cmsg := C.CString(err.Error())
+	defer C.free(unsafe.Pointer(cmsg))
+
+	C.giterr_set_str(C.int(class), cmsg)
+
+	if code, ok := backendErrorCodes[err]; ok {
+		return code
+	}
+	return C.GIT_ERROR
+}
+
+func isNotFound(err error) bool {
+	gitErr, ok := err.(*gitError)
+	return ok && gitErr.code == errNotFound
+}
+
+func isAmbiguous(err error) bool {
+	gitErr, ok := err.(*gitError)
+	return ok && gitErr.code == errAmbigious
+}
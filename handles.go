@@ -0,0 +1,46 @@
+package libgit2
+
+//#include <stdlib.h>
+import "C"
+
+import (
+	"sync"
+	"unsafe"
+)
+
+// handleList maps opaque C pointers to Go values, so that Go values can be
+// passed through libgit2 callback payloads without handing Go pointers to C.
+type handleList struct {
+	sync.RWMutex
+
+	handles map[unsafe.Pointer]interface{}
+}
+
+var pointerHandles = &handleList{
+	handles: map[unsafe.Pointer]interface{}{},
+}
+
+func (l *handleList) get(handle unsafe.Pointer) interface{} {
+	l.RLock()
+	defer l.RUnlock()
+
+	return l.handles[handle]
+}
+
+func (l *handleList) track(v interface{}) unsafe.Pointer {
+	handle := C.malloc(1)
+
+	l.Lock()
+	defer l.Unlock()
+
+	l.handles[handle] = v
+	return handle
+}
+
+func (l *handleList) untrack(handle unsafe.Pointer) {
+	l.Lock()
+	defer l.Unlock()
+
+	delete(l.handles, handle)
+	C.free(handle)
+}
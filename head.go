@@ -0,0 +1,78 @@
+package libgit2
+
+import "strings"
+
+// headName describes the current HEAD for a reflog message: the short name of
+// the branch it points to, or the commit ID if HEAD is detached.
+func headName(repo Repository) string {
+	ref, err := gitReferenceLookup(repo.gitRepository, "HEAD")
+	if err != nil {
+		return "HEAD"
+	}
+	defer ref.free()
+
+	if target := gitReferenceSymbolicTarget(ref); target != "" {
+		return strings.TrimPrefix(target, "refs/heads/")
+	}
+	return gitReferenceTarget(ref).String()
+}
+
+func setHead(repo Repository, refname string, options []HeadOption) error {
+	config := &headConfig{repo: repo}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(strings.TrimPrefix(refname, "refs/heads/")); err != nil {
+		return err
+	}
+
+	return gitRepositorySetHead(repo.gitRepository, refname,
+		config.sig.gitSignature, config.logMessage)
+}
+
+func setHeadDetached(repo Repository, oid OID, source string, options []HeadOption) error {
+	config := &headConfig{repo: repo}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(source); err != nil {
+		return err
+	}
+
+	return gitRepositorySetHeadDetached(repo.gitRepository, oid,
+		config.sig.gitSignature, config.logMessage)
+}
+
+func detachHead(repo Repository, options []HeadOption) error {
+	tip, err := repo.tip()
+	if err != nil {
+		return err
+	}
+
+	config := &headConfig{repo: repo}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(tip.String()); err != nil {
+		return err
+	}
+
+	return gitRepositoryDetachHead(repo.gitRepository, config.sig.gitSignature,
+		config.logMessage)
+}
+
+func setHeadDetachedFromRevision(repo Repository, spec string, options []HeadOption) error {
+	obj, err := gitRevparseSingle(repo.gitRepository, spec)
+	if err != nil {
+		return err
+	}
+	defer obj.free()
+
+	commit, err := gitObjectPeel(obj, ObjectCommit)
+	if err != nil {
+		return err
+	}
+	defer commit.free()
+
+	return setHeadDetached(repo, gitObjectID(commit), spec, options)
+}
@@ -0,0 +1,44 @@
+package libgit2
+
+type headConfig struct {
+	repo Repository
+
+	sig        *Signature
+	logMessage string
+}
+
+func (c *headConfig) check(target string) error {
+	var err error
+
+	if c.sig == nil {
+		if c.sig, err = c.repo.DefaultSignature(); err != nil {
+			return err
+		}
+	}
+
+	if c.logMessage == "" {
+		c.logMessage = "checkout: moving from " + headName(c.repo) + " to " + target
+	}
+
+	return nil
+}
+
+// HeadOption is an option type for moving HEAD.
+type HeadOption func(*headConfig)
+
+// HeadSignature sets the identity used to populate the reflog entry of HEAD.
+// By default the repository's default signature is used.
+func HeadSignature(sig *Signature) HeadOption {
+	return func(c *headConfig) {
+		c.sig = sig
+	}
+}
+
+// HeadLogMessage sets the message of the reflog entry of HEAD. By default the
+// message is "checkout: moving from <old> to <new>", as written by git
+// checkout.
+func HeadLogMessage(message string) HeadOption {
+	return func(c *headConfig) {
+		c.logMessage = message
+	}
+}
@@ -0,0 +1,105 @@
+package libgit2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetHead(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 2)
+	if _, err := repo.CreateBranch("dev"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetHead("refs/heads/dev", HeadLogMessage("switch to dev")); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "dev", headName(*repo); want != got {
+		t.Errorf("want HEAD at %q, got %q", want, got)
+	}
+	mustHaveHeadLog(t, repo, "switch to dev")
+
+	if err := repo.SetHead("refs/heads/unborn"); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.isUnbornHead() {
+		t.Error("want unborn HEAD")
+	}
+	mustHaveHeadLog(t, repo, "checkout: moving from dev to unborn")
+
+	if err := repo.SetHead("refs/heads/master"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.DetachHead(); err != nil {
+		t.Fatal(err)
+	}
+	tip, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.isDetachedHead() {
+		t.Error("want detached HEAD")
+	}
+	mustHaveHeadLog(t, repo, "checkout: moving from master to "+tip.String())
+}
+
+func TestSetHeadDetached(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 2)
+
+	tip, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parents, err := tip.Parents()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := repo.DefaultSignature()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetHeadDetached(parents[0].ID(), HeadSignature(sig)); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := parents[0].String(), headName(*repo); want != got {
+		t.Errorf("want HEAD at %s, got %s", want, got)
+	}
+	mustHaveHeadLog(t, repo, "checkout: moving from master to "+parents[0].String())
+
+	if err := repo.SetHeadDetachedFromRevision("master"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := tip.String(), headName(*repo); want != got {
+		t.Errorf("want HEAD at %s, got %s", want, got)
+	}
+	mustHaveHeadLog(t, repo, "checkout: moving from "+parents[0].String()+" to master")
+
+	if err := repo.SetHeadDetachedFromRevision(rndstr()); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func mustHaveHeadLog(t *testing.T, repo *Repository, message string) {
+	data, err := ioutil.ReadFile(filepath.Join(repo.Path(), "logs", "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if last := lines[len(lines)-1]; !strings.HasSuffix(last, "\t"+message) {
+		t.Errorf("want HEAD reflog entry %q, got %q", message, last)
+	}
+}
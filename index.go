@@ -4,14 +4,20 @@ package libgit2
 import "C"
 
 import (
+	"errors"
 	"os"
 	"runtime"
+	"sync"
 	"unsafe"
 )
 
+var errIndexClosed = errors.New("index is closed")
+
 // Index is the in-memory representation of an index file.
 type Index struct {
 	*gitIndex
+
+	repo Repository
 }
 
 func repositoryIndex(repo Repository) (*Index, error) {
@@ -19,31 +25,76 @@ func repositoryIndex(repo Repository) (*Index, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Index{i}, nil
+	return &Index{i, repo}, nil
+}
+
+// Close releases the index. It may be called more than once.
+func (i Index) Close() error {
+	i.free()
+	return nil
 }
 
 // AddPath adds a file by path to the index.
 func (i Index) AddPath(path string) error {
+	if err := i.acquire(); err != nil {
+		return err
+	}
+	defer i.release()
+
 	return gitIndexAddBypath(i.gitIndex, path)
 }
 
-// Get file info for a file in the index.
+// Get file info for a file in the index. It returns nil once the index or
+// its repository is closed.
 func (i Index) Get(path string) os.FileInfo {
+	if i.acquire() != nil {
+		return nil
+	}
+	defer i.release()
+
 	return gitIndexGetBypath(i.gitIndex, path, 0)
 }
 
 // Save the index on-disk.
 func (i Index) Write() error {
+	if err := i.acquire(); err != nil {
+		return err
+	}
+	defer i.release()
+
 	return gitIndexWrite(i.gitIndex)
 }
 
 // WriteTree writes the index as a tree.
 func (i Index) WriteTree(repo Repository) (*Tree, error) {
+	if err := i.acquire(); err != nil {
+		return nil, err
+	}
+	defer i.release()
+
 	oid, err := gitIndexWriteTree(i.gitIndex)
 	if err != nil {
 		return nil, err
 	}
-	return lookupTree(repo, OID{oid})
+	return lookupTree(repo, oid)
+}
+
+// acquire holds the index and its repository open for the duration of a
+// call, and fails once either is closed.
+func (i Index) acquire() error {
+	if err := i.gitIndex.acquire(); err != nil {
+		return err
+	}
+	if err := i.repo.acquire(); err != nil {
+		i.gitIndex.release()
+		return err
+	}
+	return nil
+}
+
+func (i Index) release() {
+	i.repo.release()
+	i.gitIndex.release()
 }
 
 func (i Index) entryCount() uint {
@@ -52,6 +103,33 @@ func (i Index) entryCount() uint {
 
 type gitIndex struct {
 	ptr *C.git_index
+
+	mu     sync.Mutex
+	calls  int
+	closed bool
+}
+
+// acquire holds the index open for the duration of a call, so that closing
+// the index waits for in-flight calls. It fails once the index is closed.
+func (i *gitIndex) acquire() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.closed {
+		return errIndexClosed
+	}
+	i.calls++
+	return nil
+}
+
+func (i *gitIndex) release() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.calls--; i.closed && i.calls == 0 {
+		C.git_index_free(i.ptr)
+		i.ptr = nil
+	}
 }
 
 func (i *gitIndex) init() {
@@ -60,7 +138,17 @@ func (i *gitIndex) init() {
 
 func (i *gitIndex) free() {
 	runtime.SetFinalizer(i, nil)
-	C.git_index_free(i.ptr)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.closed {
+		return
+	}
+	if i.closed = true; i.calls == 0 {
+		C.git_index_free(i.ptr)
+		i.ptr = nil
+	}
 }
 
 func gitIndexAddBypath(idx *gitIndex, path string) error {
@@ -74,13 +162,43 @@ func gitIndexEntrycount(idx *gitIndex) uint {
 	return uint(C.git_index_entrycount(idx.ptr))
 }
 
+func gitIndexNew() (*gitIndex, error) {
+	i := new(gitIndex)
+
+	if err := unwrapErr(C.libgit2_index_new(&i.ptr)); err != nil {
+		return nil, err
+	}
+	i.init()
+	return i, nil
+}
+
+func gitIndexOpen(path string) (*gitIndex, error) {
+	i := new(gitIndex)
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	if err := unwrapErr(C.libgit2_index_open(&i.ptr, cpath)); err != nil {
+		return nil, err
+	}
+	i.init()
+	return i, nil
+}
+
+func gitIndexReadTree(idx *gitIndex, tree *gitTree) error {
+	return unwrapErr(C.libgit2_index_read_tree(idx.ptr, tree.ptr))
+}
+
 func gitIndexWrite(idx *gitIndex) error {
 	return unwrapErr(C.libgit2_index_write(idx.ptr))
 }
 
-func gitIndexWriteTree(idx *gitIndex) (*gitOID, error) {
-	oid := &gitOID{ptr: &C.git_oid{}}
-	return oid, unwrapErr(C.libgit2_index_write_tree(oid.ptr, idx.ptr))
+func gitIndexWriteTree(idx *gitIndex) (OID, error) {
+	var oid OID
+	if err := unwrapErr(C.libgit2_index_write_tree(oid.ptr(), idx.ptr)); err != nil {
+		return OID{}, err
+	}
+	return oid, nil
 }
 
 func gitRepositoryIndex(repo *gitRepository) (*gitIndex, error) {
@@ -90,3 +90,38 @@ func popd(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestIndexClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	f := mustSeedTestFile(t, repo)
+
+	idx, err := repo.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddPath(f); err != errIndexClosed {
+		t.Errorf("want error %q, got %v", errIndexClosed, err)
+	}
+	if info := idx.Get(f); info != nil {
+		t.Errorf("want no file info from a closed index, got %v", info)
+	}
+
+	if idx, err = repo.Index(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Write(); err != ErrClosed {
+		t.Errorf("want error %q, got %v", ErrClosed, err)
+	}
+}
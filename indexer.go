@@ -0,0 +1,57 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"runtime"
+	"unsafe"
+)
+
+type gitIndexer struct {
+	ptr *C.git_indexer
+}
+
+func (i *gitIndexer) init() {
+	runtime.SetFinalizer(i, (*gitIndexer).free)
+}
+
+func (i *gitIndexer) free() {
+	runtime.SetFinalizer(i, nil)
+	C.git_indexer_free(i.ptr)
+	i.ptr = nil
+}
+
+// gitIndexerWrite indexes pack and writes the packfile and its index into
+// dir.
+func gitIndexerWrite(dir string, odb *gitODB, pack []byte) error {
+	idx, err := gitIndexerNew(dir, odb)
+	if err != nil {
+		return err
+	}
+	defer idx.free()
+
+	stats := &C.git_transfer_progress{}
+	if len(pack) > 0 {
+		err := unwrapErr(C.libgit2_indexer_append(idx.ptr, unsafe.Pointer(&pack[0]),
+			C.size_t(len(pack)), stats))
+		if err != nil {
+			return err
+		}
+	}
+	return unwrapErr(C.libgit2_indexer_commit(idx.ptr, stats))
+}
+
+func gitIndexerNew(dir string, odb *gitODB) (*gitIndexer, error) {
+	i := new(gitIndexer)
+
+	cdir := C.CString(dir)
+	defer C.free(unsafe.Pointer(cdir))
+
+	err := unwrapErr(C.libgit2_indexer_new(&i.ptr, cdir, 0, odb.ptr, nil, nil))
+	if err != nil {
+		return nil, err
+	}
+	i.init()
+	return i, nil
+}
@@ -0,0 +1,79 @@
+package libgit2
+
+type initConfig struct {
+	path string
+
+	flags repositoryInitFlag
+	mode  SharedMode
+
+	workdir, description, template, initialHead, originURL string
+}
+
+func (c *initConfig) check() error {
+	if c.flags&repositoryInitMkpath == 0 {
+		c.flags |= repositoryInitMkdir
+	}
+
+	if c.template != "" {
+		c.flags |= repositoryInitExternalTemplate
+	}
+
+	return nil
+}
+
+// InitOption is an option type for initializing a repository.
+type InitOption func(*initConfig)
+
+// Description sets the contents of the repository's description file.
+func Description(description string) InitOption {
+	return func(c *initConfig) {
+		c.description = description
+	}
+}
+
+// InitBare initializes a bare repository.
+func InitBare(c *initConfig) { c.flags |= repositoryInitBare }
+
+// InitialHead sets the branch HEAD points to in the new repository, such as
+// "main". The name may be a short branch name or a full reference name.
+func InitialHead(name string) InitOption {
+	return func(c *initConfig) {
+		c.initialHead = name
+	}
+}
+
+// MkPath creates any missing parent directories of the repository path. By
+// default only the last directory is created.
+func MkPath(c *initConfig) { c.flags |= repositoryInitMkpath }
+
+// Origin adds an "origin" remote with the given url to the new repository.
+func Origin(url string) InitOption {
+	return func(c *initConfig) {
+		c.originURL = url
+	}
+}
+
+// SeparateWorkdir sets a working directory for the repository that is not
+// the parent of its git directory. It is recorded as core.worktree, and a
+// .git file linking back to the repository is written in the directory.
+func SeparateWorkdir(dir string) InitOption {
+	return func(c *initConfig) {
+		c.workdir = dir
+	}
+}
+
+// Shared sets the permissions of the repository files, and records them as
+// core.sharedRepository.
+func Shared(mode SharedMode) InitOption {
+	return func(c *initConfig) {
+		c.mode = mode
+	}
+}
+
+// Template initializes the repository from the template directory dir,
+// instead of the default template.
+func Template(dir string) InitOption {
+	return func(c *initConfig) {
+		c.template = dir
+	}
+}
@@ -1,4 +1,7 @@
+#include <string.h>
+
 #include "libgit2.h"
+#include "_cgo_export.h"
 
 libgit2_result libgit2_wrap_result(const int code)
 {
@@ -12,6 +15,37 @@ libgit2_result libgit2_wrap_result(const int code)
        return res;
 }
 
+// blob.h
+
+LIBGIT2_WRAPPER(libgit2_blob_create_frombuffer(
+		git_oid *id,
+		git_repository *repo,
+		const void *buffer,
+		size_t len),
+	git_blob_create_frombuffer(id, repo, buffer, len))
+
+static int libgit2_blob_chunk_cb(
+		char *content,
+		size_t max_length,
+		void *payload)
+{
+       return libgit2BlobChunk(payload, content, max_length);
+}
+
+LIBGIT2_WRAPPER(libgit2_blob_create_fromchunks(
+		git_oid *id,
+		git_repository *repo,
+		const char *hintpath,
+		void *payload),
+	git_blob_create_fromchunks(id, repo, hintpath, libgit2_blob_chunk_cb,
+		payload))
+
+LIBGIT2_WRAPPER(libgit2_blob_create_fromworkdir(
+		git_oid *id,
+		git_repository *repo,
+		const char *relative_path),
+	git_blob_create_fromworkdir(id, repo, relative_path))
+
 // branch.h
 
 LIBGIT2_WRAPPER(libgit2_branch_create(
@@ -62,6 +96,48 @@ LIBGIT2_WRAPPER(libgit2_branch_next(
 		git_branch_iterator *iter),
 	git_branch_next(out, out_type, iter))
 
+// checkout.h
+
+LIBGIT2_WRAPPER(libgit2_checkout_init_options(
+		git_checkout_options *opts,
+		unsigned int version),
+	git_checkout_init_options(opts, version))
+
+LIBGIT2_WRAPPER(libgit2_checkout_tree(
+		git_repository *repo,
+		const git_object *treeish,
+		const git_checkout_options *opts),
+	git_checkout_tree(repo, treeish, opts))
+
+// clone.h
+
+LIBGIT2_WRAPPER(libgit2_clone(
+		git_repository **out,
+		const char *url,
+		const char *local_path,
+		const git_clone_options *options),
+	git_clone(out, url, local_path, options))
+
+LIBGIT2_WRAPPER(libgit2_clone_init_options(
+		git_clone_options *opts,
+		unsigned int version),
+	git_clone_init_options(opts, version))
+
+static int libgit2_transfer_progress_cb(
+		const git_transfer_progress *stats,
+		void *payload)
+{
+       return libgit2TransferProgress((git_transfer_progress *)stats, payload);
+}
+
+void libgit2_clone_options_set_transfer_progress(
+		git_clone_options *opts,
+		void *payload)
+{
+       opts->remote_callbacks.transfer_progress = libgit2_transfer_progress_cb;
+       opts->remote_callbacks.payload = payload;
+}
+
 // commit.h
 
 LIBGIT2_WRAPPER(libgit2_commit_create(
@@ -84,6 +160,12 @@ LIBGIT2_WRAPPER(libgit2_commit_lookup(
 		const git_oid *id),
 	git_commit_lookup(commit, repo, id))
 
+LIBGIT2_WRAPPER(libgit2_commit_nth_gen_ancestor(
+		git_commit **ancestor,
+		const git_commit *commit,
+		unsigned int n),
+	git_commit_nth_gen_ancestor(ancestor, commit, n))
+
 LIBGIT2_WRAPPER(libgit2_commit_parent(
 		git_commit **out,
 		const git_commit *commit,
@@ -94,6 +176,112 @@ LIBGIT2_WRAPPER(libgit2_commit_parentcount(
 		const git_commit *commit),
 	git_commit_parentcount(commit))
 
+LIBGIT2_WRAPPER(libgit2_commit_tree(
+		git_tree **tree_out,
+		const git_commit *commit),
+	git_commit_tree(tree_out, commit))
+
+// config.h
+
+LIBGIT2_WRAPPER(libgit2_config_add_file_ondisk(
+		git_config *cfg,
+		const char *path,
+		git_config_level_t level,
+		int force),
+	git_config_add_file_ondisk(cfg, path, level, force))
+
+LIBGIT2_WRAPPER(libgit2_config_delete_entry(
+		git_config *cfg,
+		const char *name),
+	git_config_delete_entry(cfg, name))
+
+LIBGIT2_WRAPPER(libgit2_config_delete_multivar(
+		git_config *cfg,
+		const char *name,
+		const char *regexp),
+	git_config_delete_multivar(cfg, name, regexp))
+
+LIBGIT2_WRAPPER(libgit2_config_get_bool(
+		int *out,
+		const git_config *cfg,
+		const char *name),
+	git_config_get_bool(out, cfg, name))
+
+LIBGIT2_WRAPPER(libgit2_config_get_entry(
+		const git_config_entry **out,
+		const git_config *cfg,
+		const char *name),
+	git_config_get_entry(out, cfg, name))
+
+LIBGIT2_WRAPPER(libgit2_config_get_int64(
+		int64_t *out,
+		const git_config *cfg,
+		const char *name),
+	git_config_get_int64(out, cfg, name))
+
+LIBGIT2_WRAPPER(libgit2_config_iterator_glob_new(
+		git_config_iterator **out,
+		const git_config *cfg,
+		const char *regexp),
+	git_config_iterator_glob_new(out, cfg, regexp))
+
+LIBGIT2_WRAPPER(libgit2_config_multivar_iterator_new(
+		git_config_iterator **out,
+		const git_config *cfg,
+		const char *name,
+		const char *regexp),
+	git_config_multivar_iterator_new(out, cfg, name, regexp))
+
+LIBGIT2_WRAPPER(libgit2_config_next(
+		git_config_entry **entry,
+		git_config_iterator *iter),
+	git_config_next(entry, iter))
+
+LIBGIT2_WRAPPER(libgit2_config_open_default(
+		git_config **out),
+	git_config_open_default(out))
+
+LIBGIT2_WRAPPER(libgit2_config_open_level(
+		git_config **out,
+		const git_config *parent,
+		git_config_level_t level),
+	git_config_open_level(out, parent, level))
+
+LIBGIT2_WRAPPER(libgit2_config_open_ondisk(
+		git_config **out,
+		const char *path),
+	git_config_open_ondisk(out, path))
+
+LIBGIT2_WRAPPER(libgit2_config_set_bool(
+		git_config *cfg,
+		const char *name,
+		int value),
+	git_config_set_bool(cfg, name, value))
+
+LIBGIT2_WRAPPER(libgit2_config_set_int64(
+		git_config *cfg,
+		const char *name,
+		int64_t value),
+	git_config_set_int64(cfg, name, value))
+
+LIBGIT2_WRAPPER(libgit2_config_set_multivar(
+		git_config *cfg,
+		const char *name,
+		const char *regexp,
+		const char *value),
+	git_config_set_multivar(cfg, name, regexp, value))
+
+LIBGIT2_WRAPPER(libgit2_config_set_string(
+		git_config *cfg,
+		const char *name,
+		const char *value),
+	git_config_set_string(cfg, name, value))
+
+LIBGIT2_WRAPPER(libgit2_config_snapshot(
+		git_config **out,
+		git_config *config),
+	git_config_snapshot(out, config))
+
 // index.h
 
 LIBGIT2_WRAPPER(libgit2_index_add_bypath(
@@ -101,6 +289,20 @@ LIBGIT2_WRAPPER(libgit2_index_add_bypath(
 		const char *path),
 	git_index_add_bypath(index, path))
 
+LIBGIT2_WRAPPER(libgit2_index_new(
+		git_index **out),
+	git_index_new(out))
+
+LIBGIT2_WRAPPER(libgit2_index_open(
+		git_index **out,
+		const char *index_path),
+	git_index_open(out, index_path))
+
+LIBGIT2_WRAPPER(libgit2_index_read_tree(
+		git_index *index,
+		const git_tree *tree),
+	git_index_read_tree(index, tree))
+
 LIBGIT2_WRAPPER(libgit2_index_write(
 		git_index *index),
 	git_index_write(index))
@@ -110,6 +312,50 @@ LIBGIT2_WRAPPER(libgit2_index_write_tree(
 		git_index *index),
 	git_index_write_tree(out, index))
 
+// indexer.h
+
+LIBGIT2_WRAPPER(libgit2_indexer_append(
+		git_indexer *idx,
+		const void *data,
+		size_t size,
+		git_transfer_progress *stats),
+	git_indexer_append(idx, data, size, stats))
+
+LIBGIT2_WRAPPER(libgit2_indexer_commit(
+		git_indexer *idx,
+		git_transfer_progress *stats),
+	git_indexer_commit(idx, stats))
+
+LIBGIT2_WRAPPER(libgit2_indexer_new(
+		git_indexer **out,
+		const char *path,
+		unsigned int mode,
+		git_odb *odb,
+		git_transfer_progress_cb progress_cb,
+		void *progress_cb_payload),
+	git_indexer_new(out, path, mode, odb, progress_cb, progress_cb_payload))
+
+// mempack.h
+
+LIBGIT2_WRAPPER(libgit2_mempack_dump(
+		git_buf *pack,
+		git_repository *repo,
+		git_odb_backend *backend),
+	git_mempack_dump(pack, repo, backend))
+
+LIBGIT2_WRAPPER(libgit2_mempack_new(
+		git_odb_backend **out),
+	git_mempack_new(out))
+
+// merge.h
+
+LIBGIT2_WRAPPER(libgit2_merge_bases(
+		git_oidarray *out,
+		git_repository *repo,
+		const git_oid *one,
+		const git_oid *two),
+	git_merge_bases(out, repo, one, two))
+
 // message.h
 
 LIBGIT2_WRAPPER(libgit2_message_prettify(
@@ -121,13 +367,855 @@ LIBGIT2_WRAPPER(libgit2_message_prettify(
 
 // object.h
 
+LIBGIT2_WRAPPER(libgit2_object_dup(
+		git_object **dest,
+		git_object *source),
+	git_object_dup(dest, source))
+
+LIBGIT2_WRAPPER(libgit2_object_lookup(
+		git_object **object,
+		git_repository *repo,
+		const git_oid *id,
+		git_otype type),
+	git_object_lookup(object, repo, id, type))
+
+LIBGIT2_WRAPPER(libgit2_object_lookup_prefix(
+		git_object **object_out,
+		git_repository *repo,
+		const git_oid *id,
+		size_t len,
+		git_otype type),
+	git_object_lookup_prefix(object_out, repo, id, len, type))
+
+LIBGIT2_WRAPPER(libgit2_object_peel(
+		git_object **peeled,
+		const git_object *object,
+		git_otype target_type),
+	git_object_peel(peeled, object, target_type))
+
 LIBGIT2_WRAPPER(libgit2_object_short_id(
 		git_buf *out,
 		const git_object *obj),
 	git_object_short_id(out, obj))
 
+// odb.h
+
+LIBGIT2_WRAPPER(libgit2_odb_add_backend(
+		git_odb *odb,
+		git_odb_backend *backend,
+		int priority),
+	git_odb_add_backend(odb, backend, priority))
+
+LIBGIT2_WRAPPER(libgit2_odb_add_disk_alternate(
+		git_odb *odb,
+		const char *path),
+	git_odb_add_disk_alternate(odb, path))
+
+LIBGIT2_WRAPPER(libgit2_odb_exists_prefix(
+		git_oid *out,
+		git_odb *db,
+		const git_oid *short_id,
+		size_t len),
+	git_odb_exists_prefix(out, db, short_id, len))
+
+static int libgit2_odb_foreach_cb(
+		const git_oid *id,
+		void *payload)
+{
+       return libgit2ODBForeach(payload, (git_oid *)id);
+}
+
+LIBGIT2_WRAPPER(libgit2_odb_foreach(
+		git_odb *db,
+		void *payload),
+	git_odb_foreach(db, libgit2_odb_foreach_cb, payload))
+
+LIBGIT2_WRAPPER(libgit2_odb_new(
+		git_odb **out),
+	git_odb_new(out))
+
+LIBGIT2_WRAPPER(libgit2_odb_open(
+		git_odb **out,
+		const char *objects_dir),
+	git_odb_open(out, objects_dir))
+
+LIBGIT2_WRAPPER(libgit2_odb_open_wstream(
+		git_odb_stream **out,
+		git_odb *db,
+		size_t size,
+		git_otype type),
+	git_odb_open_wstream(out, db, size, type))
+
+LIBGIT2_WRAPPER(libgit2_odb_read(
+		git_odb_object **out,
+		git_odb *db,
+		const git_oid *id),
+	git_odb_read(out, db, id))
+
+LIBGIT2_WRAPPER(libgit2_odb_read_header(
+		size_t *len_out,
+		git_otype *type_out,
+		git_odb *db,
+		const git_oid *id),
+	git_odb_read_header(len_out, type_out, db, id))
+
+LIBGIT2_WRAPPER(libgit2_odb_refresh(
+		git_odb *db),
+	git_odb_refresh(db))
+
+LIBGIT2_WRAPPER(libgit2_odb_stream_finalize_write(
+		git_oid *out,
+		git_odb_stream *stream),
+	git_odb_stream_finalize_write(out, stream))
+
+LIBGIT2_WRAPPER(libgit2_odb_stream_write(
+		git_odb_stream *stream,
+		const char *buffer,
+		size_t len),
+	git_odb_stream_write(stream, buffer, len))
+
+LIBGIT2_WRAPPER(libgit2_odb_write(
+		git_oid *out,
+		git_odb *odb,
+		const void *data,
+		size_t len,
+		git_otype type),
+	git_odb_write(out, odb, data, len, type))
+
+// odb_backend.h
+
+typedef struct libgit2_odb_backend {
+       git_odb_backend parent;
+       void *handle;
+} libgit2_odb_backend;
+
+#define LIBGIT2_ODB_HANDLE(backend) \
+	(((libgit2_odb_backend *)(backend))->handle)
+
+static int libgit2_odb_backend_read(
+		void **data_p,
+		size_t *len_p,
+		git_otype *type_p,
+		git_odb_backend *backend,
+		const git_oid *oid)
+{
+       return libgit2ObjectBackendRead(LIBGIT2_ODB_HANDLE(backend), data_p,
+               len_p, type_p, backend, (git_oid *)oid);
+}
+
+static int libgit2_odb_backend_read_prefix(
+		git_oid *out_oid,
+		void **data_p,
+		size_t *len_p,
+		git_otype *type_p,
+		git_odb_backend *backend,
+		const git_oid *short_oid,
+		size_t len)
+{
+       return libgit2ObjectBackendReadPrefix(LIBGIT2_ODB_HANDLE(backend),
+               out_oid, data_p, len_p, type_p, backend, (git_oid *)short_oid,
+               len);
+}
+
+static int libgit2_odb_backend_read_header(
+		size_t *len_p,
+		git_otype *type_p,
+		git_odb_backend *backend,
+		const git_oid *oid)
+{
+       return libgit2ObjectBackendReadHeader(LIBGIT2_ODB_HANDLE(backend), len_p,
+               type_p, (git_oid *)oid);
+}
+
+static int libgit2_odb_backend_write(
+		git_odb_backend *backend,
+		const git_oid *oid,
+		const void *data,
+		size_t len,
+		git_otype type)
+{
+       return libgit2ObjectBackendWrite(LIBGIT2_ODB_HANDLE(backend),
+               (git_oid *)oid, (void *)data, len, type);
+}
+
+static int libgit2_odb_backend_exists(
+		git_odb_backend *backend,
+		const git_oid *oid)
+{
+       return libgit2ObjectBackendExists(LIBGIT2_ODB_HANDLE(backend),
+               (git_oid *)oid);
+}
+
+static int libgit2_odb_backend_refresh(
+		git_odb_backend *backend)
+{
+       return libgit2ObjectBackendRefresh(LIBGIT2_ODB_HANDLE(backend));
+}
+
+static int libgit2_odb_backend_foreach(
+		git_odb_backend *backend,
+		git_odb_foreach_cb cb,
+		void *payload)
+{
+       return libgit2ObjectBackendForeach(LIBGIT2_ODB_HANDLE(backend), cb,
+               payload);
+}
+
+static void libgit2_odb_backend_release(
+		git_odb_backend *backend)
+{
+       libgit2ObjectBackendFree(LIBGIT2_ODB_HANDLE(backend));
+       free(backend);
+}
+
+void libgit2_odb_backend_free(
+		git_odb_backend *backend)
+{
+       backend->free(backend);
+}
+
+git_odb_backend *libgit2_odb_backend_new(
+		void *handle)
+{
+       libgit2_odb_backend *b = calloc(1, sizeof(libgit2_odb_backend));
+       if (b == NULL)
+               return NULL;
+
+       b->parent.version = GIT_ODB_BACKEND_VERSION;
+       b->parent.read = libgit2_odb_backend_read;
+       b->parent.read_prefix = libgit2_odb_backend_read_prefix;
+       b->parent.read_header = libgit2_odb_backend_read_header;
+       b->parent.write = libgit2_odb_backend_write;
+       b->parent.exists = libgit2_odb_backend_exists;
+       b->parent.refresh = libgit2_odb_backend_refresh;
+       b->parent.foreach = libgit2_odb_backend_foreach;
+       b->parent.free = libgit2_odb_backend_release;
+       b->handle = handle;
+       return (git_odb_backend *)b;
+}
+
+int libgit2_odb_foreach_cb_call(
+		git_odb_foreach_cb cb,
+		const git_oid *id,
+		void *payload)
+{
+       return cb(id, payload);
+}
+
+// oid.h
+
+LIBGIT2_WRAPPER(libgit2_oid_fromstr(
+		git_oid *out,
+		const char *str),
+	git_oid_fromstr(out, str))
+
+LIBGIT2_WRAPPER(libgit2_oid_fromstrn(
+		git_oid *out,
+		const char *str,
+		size_t length),
+	git_oid_fromstrn(out, str, length))
+
+// refdb.h
+
+LIBGIT2_WRAPPER(libgit2_refdb_new(
+		git_refdb **out,
+		git_repository *repo),
+	git_refdb_new(out, repo))
+
+LIBGIT2_WRAPPER(libgit2_refdb_open(
+		git_refdb **out,
+		git_repository *repo),
+	git_refdb_open(out, repo))
+
+// refdb_backend.h
+
+LIBGIT2_WRAPPER(libgit2_refdb_backend_fs(
+		git_refdb_backend **out,
+		git_repository *repo),
+	git_refdb_backend_fs(out, repo))
+
+LIBGIT2_WRAPPER(libgit2_refdb_set_backend(
+		git_refdb *refdb,
+		git_refdb_backend *backend),
+	git_refdb_set_backend(refdb, backend))
+
+typedef struct libgit2_refdb_backend {
+       git_refdb_backend parent;
+       void *handle;
+} libgit2_refdb_backend;
+
+#define LIBGIT2_REFDB_HANDLE(backend) \
+	(((libgit2_refdb_backend *)(backend))->handle)
+
+typedef struct libgit2_reference_iterator {
+       git_reference_iterator parent;
+       void *handle;
+} libgit2_reference_iterator;
+
+#define LIBGIT2_REFITER_HANDLE(iter) \
+	(((libgit2_reference_iterator *)(iter))->handle)
+
+static int libgit2_reference_iterator_next(
+		git_reference **ref,
+		git_reference_iterator *iter)
+{
+       return libgit2RefIteratorNext(LIBGIT2_REFITER_HANDLE(iter), ref);
+}
+
+static int libgit2_reference_iterator_next_name(
+		const char **ref_name,
+		git_reference_iterator *iter)
+{
+       return libgit2RefIteratorNextName(LIBGIT2_REFITER_HANDLE(iter),
+               (char **)ref_name);
+}
+
+static void libgit2_reference_iterator_free(
+		git_reference_iterator *iter)
+{
+       libgit2RefIteratorFree(LIBGIT2_REFITER_HANDLE(iter));
+       free(iter);
+}
+
+git_reference_iterator *libgit2_reference_iterator_new(
+		void *handle)
+{
+       libgit2_reference_iterator *it = calloc(1,
+               sizeof(libgit2_reference_iterator));
+       if (it == NULL)
+               return NULL;
+
+       it->parent.next = libgit2_reference_iterator_next;
+       it->parent.next_name = libgit2_reference_iterator_next_name;
+       it->parent.free = libgit2_reference_iterator_free;
+       it->handle = handle;
+       return (git_reference_iterator *)it;
+}
+
+static int libgit2_refdb_backend_exists(
+		int *exists,
+		git_refdb_backend *backend,
+		const char *ref_name)
+{
+       return libgit2RefBackendExists(LIBGIT2_REFDB_HANDLE(backend), exists,
+               (char *)ref_name);
+}
+
+static int libgit2_refdb_backend_lookup(
+		git_reference **out,
+		git_refdb_backend *backend,
+		const char *ref_name)
+{
+       return libgit2RefBackendLookup(LIBGIT2_REFDB_HANDLE(backend), out,
+               (char *)ref_name);
+}
+
+static int libgit2_refdb_backend_iterator(
+		git_reference_iterator **iter,
+		git_refdb_backend *backend,
+		const char *glob)
+{
+       return libgit2RefBackendIterator(LIBGIT2_REFDB_HANDLE(backend), iter,
+               (char *)glob);
+}
+
+static int libgit2_refdb_backend_write(
+		git_refdb_backend *backend,
+		const git_reference *ref,
+		int force,
+		const git_signature *who,
+		const char *message,
+		const git_oid *old,
+		const char *old_target)
+{
+       return libgit2RefBackendWrite(LIBGIT2_REFDB_HANDLE(backend),
+               (git_reference *)ref, force, (git_signature *)who,
+               (char *)message, (git_oid *)old, (char *)old_target);
+}
+
+static int libgit2_refdb_backend_rename(
+		git_reference **out,
+		git_refdb_backend *backend,
+		const char *old_name,
+		const char *new_name,
+		int force,
+		const git_signature *who,
+		const char *message)
+{
+       return libgit2RefBackendRename(LIBGIT2_REFDB_HANDLE(backend), out,
+               (char *)old_name, (char *)new_name, force,
+               (git_signature *)who, (char *)message);
+}
+
+static int libgit2_refdb_backend_del(
+		git_refdb_backend *backend,
+		const char *ref_name,
+		const git_oid *old_id,
+		const char *old_target)
+{
+       return libgit2RefBackendDel(LIBGIT2_REFDB_HANDLE(backend),
+               (char *)ref_name, (git_oid *)old_id, (char *)old_target);
+}
+
+static int libgit2_refdb_backend_compress(
+		git_refdb_backend *backend)
+{
+       return libgit2RefBackendCompress(LIBGIT2_REFDB_HANDLE(backend));
+}
+
+static int libgit2_refdb_backend_has_log(
+		git_refdb_backend *backend,
+		const char *refname)
+{
+       return libgit2RefBackendHasLog(LIBGIT2_REFDB_HANDLE(backend),
+               (char *)refname);
+}
+
+static int libgit2_refdb_backend_ensure_log(
+		git_refdb_backend *backend,
+		const char *refname)
+{
+       return libgit2RefBackendEnsureLog(LIBGIT2_REFDB_HANDLE(backend),
+               (char *)refname);
+}
+
+// libgit2 has no constructor for git_reflog, which reflog_read must return,
+// and no accessor for the name of a log handed to reflog_write.
+// libgit2_reflog mirrors struct git_reflog of src/reflog.h so that an empty
+// log can be allocated with the default allocator and filled with
+// git_reflog_append. libgit2 sets db itself, and releases the log with
+// git_reflog_free.
+typedef struct libgit2_reflog {
+       git_refdb *db;
+       char *ref_name;
+       struct {
+               size_t alloc_size;
+               void *cmp;
+               void **contents;
+               size_t length;
+               uint32_t flags;
+       } entries;
+} libgit2_reflog;
+
+static int libgit2_refdb_backend_reflog_read(
+		git_reflog **out,
+		git_refdb_backend *backend,
+		const char *name)
+{
+       int error;
+       libgit2_reflog *log = calloc(1, sizeof(libgit2_reflog));
+       if (log == NULL)
+               return -1;
+
+       if ((log->ref_name = strdup(name)) == NULL) {
+               free(log);
+               return -1;
+       }
+
+       if ((error = libgit2RefBackendReflogRead(LIBGIT2_REFDB_HANDLE(backend),
+                       (git_reflog *)log, (char *)name)) < 0) {
+               git_reflog_free((git_reflog *)log);
+               return error;
+       }
+
+       *out = (git_reflog *)log;
+       return 0;
+}
+
+static int libgit2_refdb_backend_reflog_write(
+		git_refdb_backend *backend,
+		git_reflog *reflog)
+{
+       return libgit2RefBackendReflogWrite(LIBGIT2_REFDB_HANDLE(backend),
+               reflog, ((libgit2_reflog *)reflog)->ref_name);
+}
+
+static int libgit2_refdb_backend_reflog_rename(
+		git_refdb_backend *backend,
+		const char *old_name,
+		const char *new_name)
+{
+       return libgit2RefBackendReflogRename(LIBGIT2_REFDB_HANDLE(backend),
+               (char *)old_name, (char *)new_name);
+}
+
+static int libgit2_refdb_backend_reflog_delete(
+		git_refdb_backend *backend,
+		const char *name)
+{
+       return libgit2RefBackendReflogDelete(LIBGIT2_REFDB_HANDLE(backend),
+               (char *)name);
+}
+
+static int libgit2_refdb_backend_lock(
+		void **payload_out,
+		git_refdb_backend *backend,
+		const char *refname)
+{
+       int error;
+
+       if ((error = libgit2RefBackendLock(LIBGIT2_REFDB_HANDLE(backend),
+                       (char *)refname)) < 0)
+               return error;
+
+       *payload_out = strdup(refname);
+       return *payload_out == NULL ? -1 : 0;
+}
+
+static int libgit2_refdb_backend_unlock(
+		git_refdb_backend *backend,
+		void *payload,
+		int success,
+		int update_reflog,
+		const git_reference *ref,
+		const git_signature *sig,
+		const char *message)
+{
+       int error = libgit2RefBackendUnlock(LIBGIT2_REFDB_HANDLE(backend),
+               (char *)payload, success, update_reflog, (git_reference *)ref,
+               (git_signature *)sig, (char *)message);
+
+       free(payload);
+       return error;
+}
+
+static void libgit2_refdb_backend_release(
+		git_refdb_backend *backend)
+{
+       libgit2RefBackendFree(LIBGIT2_REFDB_HANDLE(backend));
+       free(backend);
+}
+
+void libgit2_refdb_backend_free(
+		git_refdb_backend *backend)
+{
+       backend->free(backend);
+}
+
+git_refdb_backend *libgit2_refdb_backend_new(
+		void *handle)
+{
+       libgit2_refdb_backend *b = calloc(1, sizeof(libgit2_refdb_backend));
+       if (b == NULL)
+               return NULL;
+
+       b->parent.version = GIT_REFDB_BACKEND_VERSION;
+       b->parent.exists = libgit2_refdb_backend_exists;
+       b->parent.lookup = libgit2_refdb_backend_lookup;
+       b->parent.iterator = libgit2_refdb_backend_iterator;
+       b->parent.write = libgit2_refdb_backend_write;
+       b->parent.rename = libgit2_refdb_backend_rename;
+       b->parent.del = libgit2_refdb_backend_del;
+       b->parent.compress = libgit2_refdb_backend_compress;
+       b->parent.has_log = libgit2_refdb_backend_has_log;
+       b->parent.ensure_log = libgit2_refdb_backend_ensure_log;
+       b->parent.free = libgit2_refdb_backend_release;
+       b->parent.reflog_read = libgit2_refdb_backend_reflog_read;
+       b->parent.reflog_write = libgit2_refdb_backend_reflog_write;
+       b->parent.reflog_rename = libgit2_refdb_backend_reflog_rename;
+       b->parent.reflog_delete = libgit2_refdb_backend_reflog_delete;
+       b->parent.lock = libgit2_refdb_backend_lock;
+       b->parent.unlock = libgit2_refdb_backend_unlock;
+       b->handle = handle;
+       return (git_refdb_backend *)b;
+}
+
+// The refdb of a linked worktree sends the references private to the
+// worktree (HEAD and the other pseudo-refs, and refs/bisect/) to the worktree
+// backend, and all other references to the backend of the common directory.
+
+typedef struct libgit2_worktree_refdb {
+       git_refdb_backend parent;
+       git_refdb_backend *common;
+       git_refdb_backend *worktree;
+} libgit2_worktree_refdb;
+
+typedef struct libgit2_worktree_refdb_payload {
+       git_refdb_backend *backend;
+       void *payload;
+} libgit2_worktree_refdb_payload;
+
+static git_refdb_backend *libgit2_worktree_refdb_route(
+		git_refdb_backend *backend,
+		const char *ref_name)
+{
+       libgit2_worktree_refdb *b = (libgit2_worktree_refdb *)backend;
+
+       if (strncmp(ref_name, "refs/", 5) != 0 ||
+                       strncmp(ref_name, "refs/bisect/", 12) == 0)
+               return b->worktree;
+       return b->common;
+}
+
+static int libgit2_worktree_refdb_exists(
+		int *exists,
+		git_refdb_backend *backend,
+		const char *ref_name)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, ref_name);
+       return b->exists(exists, b, ref_name);
+}
+
+static int libgit2_worktree_refdb_lookup(
+		git_reference **out,
+		git_refdb_backend *backend,
+		const char *ref_name)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, ref_name);
+       return b->lookup(out, b, ref_name);
+}
+
+static int libgit2_worktree_refdb_iterator(
+		git_reference_iterator **iter,
+		git_refdb_backend *backend,
+		const char *glob)
+{
+       git_refdb_backend *b = ((libgit2_worktree_refdb *)backend)->common;
+       return b->iterator(iter, b, glob);
+}
+
+static int libgit2_worktree_refdb_write(
+		git_refdb_backend *backend,
+		const git_reference *ref,
+		int force,
+		const git_signature *who,
+		const char *message,
+		const git_oid *old,
+		const char *old_target)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend,
+               git_reference_name(ref));
+       return b->write(b, ref, force, who, message, old, old_target);
+}
+
+static int libgit2_worktree_refdb_rename(
+		git_reference **out,
+		git_refdb_backend *backend,
+		const char *old_name,
+		const char *new_name,
+		int force,
+		const git_signature *who,
+		const char *message)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, old_name);
+
+       if (b != libgit2_worktree_refdb_route(backend, new_name)) {
+               giterr_set_str(GITERR_REFERENCE,
+                       "cannot rename a reference in or out of a worktree");
+               return GIT_ERROR;
+       }
+       return b->rename(out, b, old_name, new_name, force, who, message);
+}
+
+static int libgit2_worktree_refdb_del(
+		git_refdb_backend *backend,
+		const char *ref_name,
+		const git_oid *old_id,
+		const char *old_target)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, ref_name);
+       return b->del(b, ref_name, old_id, old_target);
+}
+
+static int libgit2_worktree_refdb_compress(
+		git_refdb_backend *backend)
+{
+       git_refdb_backend *b = ((libgit2_worktree_refdb *)backend)->common;
+       return b->compress(b);
+}
+
+static int libgit2_worktree_refdb_has_log(
+		git_refdb_backend *backend,
+		const char *refname)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, refname);
+       return b->has_log(b, refname);
+}
+
+static int libgit2_worktree_refdb_ensure_log(
+		git_refdb_backend *backend,
+		const char *refname)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, refname);
+       return b->ensure_log(b, refname);
+}
+
+static int libgit2_worktree_refdb_reflog_read(
+		git_reflog **out,
+		git_refdb_backend *backend,
+		const char *name)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, name);
+       return b->reflog_read(out, b, name);
+}
+
+// Logs read by either backend share the layout of libgit2_reflog, so a log
+// is written back to the backend of the reference it was read for.
+static int libgit2_worktree_refdb_reflog_write(
+		git_refdb_backend *backend,
+		git_reflog *reflog)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend,
+               ((libgit2_reflog *)reflog)->ref_name);
+       return b->reflog_write(b, reflog);
+}
+
+static int libgit2_worktree_refdb_reflog_rename(
+		git_refdb_backend *backend,
+		const char *old_name,
+		const char *new_name)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, old_name);
+       return b->reflog_rename(b, old_name, new_name);
+}
+
+static int libgit2_worktree_refdb_reflog_delete(
+		git_refdb_backend *backend,
+		const char *name)
+{
+       git_refdb_backend *b = libgit2_worktree_refdb_route(backend, name);
+       return b->reflog_delete(b, name);
+}
+
+static int libgit2_worktree_refdb_lock(
+		void **payload_out,
+		git_refdb_backend *backend,
+		const char *refname)
+{
+       int error;
+       libgit2_worktree_refdb_payload *lock = calloc(1,
+               sizeof(libgit2_worktree_refdb_payload));
+       if (lock == NULL)
+               return -1;
+
+       lock->backend = libgit2_worktree_refdb_route(backend, refname);
+       if ((error = lock->backend->lock(&lock->payload, lock->backend,
+                       refname)) < 0) {
+               free(lock);
+               return error;
+       }
+
+       *payload_out = lock;
+       return 0;
+}
+
+static int libgit2_worktree_refdb_unlock(
+		git_refdb_backend *backend,
+		void *payload,
+		int success,
+		int update_reflog,
+		const git_reference *ref,
+		const git_signature *sig,
+		const char *message)
+{
+       libgit2_worktree_refdb_payload *lock = payload;
+       int error = lock->backend->unlock(lock->backend, lock->payload,
+               success, update_reflog, ref, sig, message);
+
+       free(lock);
+       return error;
+}
+
+static void libgit2_worktree_refdb_release(
+		git_refdb_backend *backend)
+{
+       libgit2_worktree_refdb *b = (libgit2_worktree_refdb *)backend;
+
+       b->common->free(b->common);
+       b->worktree->free(b->worktree);
+       free(b);
+}
+
+git_refdb_backend *libgit2_worktree_refdb_new(
+		git_refdb_backend *common,
+		git_refdb_backend *worktree)
+{
+       libgit2_worktree_refdb *b = calloc(1, sizeof(libgit2_worktree_refdb));
+       if (b == NULL)
+               return NULL;
+
+       b->parent.version = GIT_REFDB_BACKEND_VERSION;
+       b->parent.exists = libgit2_worktree_refdb_exists;
+       b->parent.lookup = libgit2_worktree_refdb_lookup;
+       b->parent.iterator = libgit2_worktree_refdb_iterator;
+       b->parent.write = libgit2_worktree_refdb_write;
+       b->parent.rename = libgit2_worktree_refdb_rename;
+       b->parent.del = libgit2_worktree_refdb_del;
+       b->parent.compress = libgit2_worktree_refdb_compress;
+       b->parent.has_log = libgit2_worktree_refdb_has_log;
+       b->parent.ensure_log = libgit2_worktree_refdb_ensure_log;
+       b->parent.free = libgit2_worktree_refdb_release;
+       b->parent.reflog_read = libgit2_worktree_refdb_reflog_read;
+       b->parent.reflog_write = libgit2_worktree_refdb_reflog_write;
+       b->parent.reflog_rename = libgit2_worktree_refdb_reflog_rename;
+       b->parent.reflog_delete = libgit2_worktree_refdb_reflog_delete;
+       b->parent.lock = libgit2_worktree_refdb_lock;
+       b->parent.unlock = libgit2_worktree_refdb_unlock;
+       b->common = common;
+       b->worktree = worktree;
+       return (git_refdb_backend *)b;
+}
+
+// reflog.h
+
+LIBGIT2_WRAPPER(libgit2_reflog_append(
+		git_reflog *reflog,
+		const git_oid *id,
+		const git_signature *committer,
+		const char *msg),
+	git_reflog_append(reflog, id, committer, msg))
+
+// refs.h
+
+LIBGIT2_WRAPPER(libgit2_reference_iterator_glob_new(
+		git_reference_iterator **out,
+		git_repository *repo,
+		const char *glob),
+	git_reference_iterator_glob_new(out, repo, glob))
+
+LIBGIT2_WRAPPER(libgit2_reference_lookup(
+		git_reference **out,
+		git_repository *repo,
+		const char *name),
+	git_reference_lookup(out, repo, name))
+
+LIBGIT2_WRAPPER(libgit2_reference_name_to_id(
+		git_oid *out,
+		git_repository *repo,
+		const char *name),
+	git_reference_name_to_id(out, repo, name))
+
+LIBGIT2_WRAPPER(libgit2_reference_next(
+		git_reference **out,
+		git_reference_iterator *iter),
+	git_reference_next(out, iter))
+
+LIBGIT2_WRAPPER(libgit2_reference_peel(
+		git_object **out,
+		git_reference *ref,
+		git_otype type),
+	git_reference_peel(out, ref, type))
+
 // repository.h
 
+LIBGIT2_WRAPPER(libgit2_repository_config(
+		git_config **out,
+		git_repository *repo),
+	git_repository_config(out, repo))
+
+LIBGIT2_WRAPPER(libgit2_repository_detach_head(
+		git_repository *repo,
+		const git_signature *signature,
+		const char *reflog_message),
+	git_repository_detach_head(repo, signature, reflog_message))
+
+LIBGIT2_WRAPPER(libgit2_repository_discover(
+		git_buf *out,
+		const char *start_path,
+		int across_fs,
+		const char *ceiling_dirs),
+	git_repository_discover(out, start_path, across_fs, ceiling_dirs))
+
 LIBGIT2_WRAPPER(libgit2_repository_head(
 		git_reference **out,
 		git_repository *repo),
@@ -148,13 +1236,104 @@ LIBGIT2_WRAPPER(libgit2_repository_init(
 		unsigned int is_bare),
 	git_repository_init(out, path, is_bare))
 
+LIBGIT2_WRAPPER(libgit2_repository_init_ext(
+		git_repository **out,
+		const char *repo_path,
+		git_repository_init_options *opts),
+	git_repository_init_ext(out, repo_path, opts))
+
+LIBGIT2_WRAPPER(libgit2_repository_init_init_options(
+		git_repository_init_options *opts,
+		unsigned int version),
+	git_repository_init_init_options(opts, version))
+
+LIBGIT2_WRAPPER(libgit2_repository_message(
+		git_buf *out,
+		git_repository *repo),
+	git_repository_message(out, repo))
+
+LIBGIT2_WRAPPER(libgit2_repository_message_remove(
+		git_repository *repo),
+	git_repository_message_remove(repo))
+
+LIBGIT2_WRAPPER(libgit2_repository_new(
+		git_repository **out),
+	git_repository_new(out))
+
+LIBGIT2_WRAPPER(libgit2_repository_odb(
+		git_odb **out,
+		git_repository *repo),
+	git_repository_odb(out, repo))
+
 LIBGIT2_WRAPPER(libgit2_repository_open(
 		git_repository **out,
 		const char *path),
 	git_repository_open(out, path))
 
+LIBGIT2_WRAPPER(libgit2_repository_open_ext(
+		git_repository **out,
+		const char *path,
+		unsigned int flags,
+		const char *ceiling_dirs),
+	git_repository_open_ext(out, path, flags, ceiling_dirs))
+
+LIBGIT2_WRAPPER(libgit2_repository_set_head(
+		git_repository *repo,
+		const char *refname,
+		const git_signature *signature,
+		const char *log_message),
+	git_repository_set_head(repo, refname, signature, log_message))
+
+LIBGIT2_WRAPPER(libgit2_repository_set_head_detached(
+		git_repository *repo,
+		const git_oid *commitish,
+		const git_signature *signature,
+		const char *log_message),
+	git_repository_set_head_detached(repo, commitish, signature, log_message))
+
+LIBGIT2_WRAPPER(libgit2_repository_set_namespace(
+		git_repository *repo,
+		const char *nmspace),
+	git_repository_set_namespace(repo, nmspace))
+
+LIBGIT2_WRAPPER(libgit2_repository_set_workdir(
+		git_repository *repo,
+		const char *workdir,
+		int update_gitlink),
+	git_repository_set_workdir(repo, workdir, update_gitlink))
+
+LIBGIT2_WRAPPER(libgit2_repository_state_cleanup(
+		git_repository *repo),
+	git_repository_state_cleanup(repo))
+
+// revparse.h
+
+LIBGIT2_WRAPPER(libgit2_revparse(
+		git_revspec *revspec,
+		git_repository *repo,
+		const char *spec),
+	git_revparse(revspec, repo, spec))
+
+LIBGIT2_WRAPPER(libgit2_revparse_ext(
+		git_object **object_out,
+		git_reference **reference_out,
+		git_repository *repo,
+		const char *spec),
+	git_revparse_ext(object_out, reference_out, repo, spec))
+
+LIBGIT2_WRAPPER(libgit2_revparse_single(
+		git_object **out,
+		git_repository *repo,
+		const char *spec),
+	git_revparse_single(out, repo, spec))
+
 // revwalk.h
 
+LIBGIT2_WRAPPER(libgit2_revwalk_hide(
+		git_revwalk *walk,
+		const git_oid *commit_id),
+	git_revwalk_hide(walk, commit_id))
+
 LIBGIT2_WRAPPER(libgit2_revwalk_new(
 		git_revwalk **out,
 		git_repository *repo),
@@ -165,6 +1344,11 @@ LIBGIT2_WRAPPER(libgit2_revwalk_next(
 		git_revwalk *walk),
 	git_revwalk_next(out, walk))
 
+LIBGIT2_WRAPPER(libgit2_revwalk_push(
+		git_revwalk *walk,
+		const git_oid *id),
+	git_revwalk_push(walk, id))
+
 LIBGIT2_WRAPPER(libgit2_revwalk_push_head(
 		git_revwalk *walk),
 	git_revwalk_push_head(walk))
@@ -181,10 +1365,106 @@ LIBGIT2_WRAPPER(libgit2_signature_dup(
 		const git_signature *sig),
 	git_signature_dup(dest, sig))
 
+LIBGIT2_WRAPPER(libgit2_signature_new(
+		git_signature **out,
+		const char *name,
+		const char *email,
+		git_time_t time,
+		int offset),
+	git_signature_new(out, name, email, time, offset))
+
+// tag.h
+
+LIBGIT2_WRAPPER(libgit2_tag_create(
+		git_oid *oid,
+		git_repository *repo,
+		const char *tag_name,
+		const git_object *target,
+		const git_signature *tagger,
+		const char *message,
+		int force),
+	git_tag_create(oid, repo, tag_name, target, tagger, message, force))
+
+LIBGIT2_WRAPPER(libgit2_tag_create_lightweight(
+		git_oid *oid,
+		git_repository *repo,
+		const char *tag_name,
+		const git_object *target,
+		int force),
+	git_tag_create_lightweight(oid, repo, tag_name, target, force))
+
+LIBGIT2_WRAPPER(libgit2_tag_delete(
+		git_repository *repo,
+		const char *tag_name),
+	git_tag_delete(repo, tag_name))
+
+LIBGIT2_WRAPPER(libgit2_tag_target(
+		git_object **target_out,
+		const git_tag *tag),
+	git_tag_target(target_out, tag))
+
 // tree.h
 
+LIBGIT2_WRAPPER(libgit2_tree_entry_bypath(
+		git_tree_entry **out,
+		const git_tree *root,
+		const char *path),
+	git_tree_entry_bypath(out, root, path))
+
 LIBGIT2_WRAPPER(libgit2_tree_lookup(
 		git_tree **out,
 		git_repository *repo,
 		const git_oid *id),
 	git_tree_lookup(out, repo, id))
+
+static int libgit2_treewalk_cb(
+		const char *root,
+		const git_tree_entry *entry,
+		void *payload)
+{
+       return libgit2TreeWalk(payload, (char *)root, (git_tree_entry *)entry);
+}
+
+LIBGIT2_WRAPPER(libgit2_tree_walk(
+		const git_tree *tree,
+		git_treewalk_mode mode,
+		void *payload),
+	git_tree_walk(tree, mode, libgit2_treewalk_cb, payload))
+
+LIBGIT2_WRAPPER(libgit2_treebuilder_create(
+		git_treebuilder **out,
+		git_repository *repo,
+		const git_tree *source),
+	git_treebuilder_create(out, repo, source))
+
+static int libgit2_treebuilder_filter_cb(
+		const git_tree_entry *entry,
+		void *payload)
+{
+       return libgit2TreebuilderFilter(payload, (git_tree_entry *)entry);
+}
+
+void libgit2_treebuilder_filter(
+		git_treebuilder *bld,
+		void *payload)
+{
+       git_treebuilder_filter(bld, libgit2_treebuilder_filter_cb, payload);
+}
+
+LIBGIT2_WRAPPER(libgit2_treebuilder_insert(
+		const git_tree_entry **out,
+		git_treebuilder *bld,
+		const char *filename,
+		const git_oid *id,
+		git_filemode_t filemode),
+	git_treebuilder_insert(out, bld, filename, id, filemode))
+
+LIBGIT2_WRAPPER(libgit2_treebuilder_remove(
+		git_treebuilder *bld,
+		const char *filename),
+	git_treebuilder_remove(bld, filename))
+
+LIBGIT2_WRAPPER(libgit2_treebuilder_write(
+		git_oid *id,
+		git_treebuilder *bld),
+	git_treebuilder_write(id, bld))
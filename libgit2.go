@@ -3,6 +3,7 @@ package libgit2
 /*
 #cgo pkg-config: --static libgit2
 #cgo LDFLAGS: -lgit2
+#include <string.h>
 #include "libgit2.h"
 */
 import "C"
@@ -27,6 +28,17 @@ func cbool(b bool) C.int {
 	return C.int(0)
 }
 
+// goBytes copies n bytes of C memory into a new Go slice. Unlike C.GoBytes
+// the length is not truncated to a C int, so objects of 2 GiB or more are
+// copied whole.
+func goBytes(p unsafe.Pointer, n C.size_t) []byte {
+	buf := make([]byte, int(n))
+	if n > 0 {
+		C.memcpy(unsafe.Pointer(&buf[0]), p, n)
+	}
+	return buf
+}
+
 func ucbool(b bool) C.uint {
 	if b {
 		return C.uint(1)
@@ -2,6 +2,11 @@
 #define _LIBGIT2_H_
 
 #include <git2.h>
+#include <git2/sys/mempack.h>
+#include <git2/sys/odb_backend.h>
+#include <git2/sys/refdb_backend.h>
+#include <git2/sys/refs.h>
+#include <git2/sys/repository.h>
 
 #define LIBGIT2_WRAPPER(sig, call) \
 const libgit2_result sig { \
@@ -15,6 +20,25 @@ typedef struct libgit2_result {
 
 libgit2_result libgit2_wrap_result(const int);
 
+// blob.h
+
+const libgit2_result libgit2_blob_create_frombuffer(
+		git_oid *id,
+		git_repository *repo,
+		const void *buffer,
+		size_t len);
+
+const libgit2_result libgit2_blob_create_fromchunks(
+		git_oid *id,
+		git_repository *repo,
+		const char *hintpath,
+		void *payload);
+
+const libgit2_result libgit2_blob_create_fromworkdir(
+		git_oid *id,
+		git_repository *repo,
+		const char *relative_path);
+
 // branch.h
 
 const libgit2_result libgit2_branch_create(
@@ -57,6 +81,33 @@ const libgit2_result libgit2_branch_next(
 		git_branch_t *out_type,
 		git_branch_iterator *iter);
 
+// checkout.h
+
+const libgit2_result libgit2_checkout_init_options(
+		git_checkout_options *opts,
+		unsigned int version);
+
+const libgit2_result libgit2_checkout_tree(
+		git_repository *repo,
+		const git_object *treeish,
+		const git_checkout_options *opts);
+
+// clone.h
+
+const libgit2_result libgit2_clone(
+		git_repository **out,
+		const char *url,
+		const char *local_path,
+		const git_clone_options *options);
+
+const libgit2_result libgit2_clone_init_options(
+		git_clone_options *opts,
+		unsigned int version);
+
+void libgit2_clone_options_set_transfer_progress(
+		git_clone_options *opts,
+		void *payload);
+
 // commit.h
 
 const libgit2_result libgit2_commit_create(
@@ -76,6 +127,11 @@ const libgit2_result libgit2_commit_lookup(
 		git_repository *repo,
 		const git_oid *id);
 
+const libgit2_result libgit2_commit_nth_gen_ancestor(
+		git_commit **ancestor,
+		const git_commit *commit,
+		unsigned int n);
+
 const libgit2_result libgit2_commit_parent(
 		git_commit **out,
 		const git_commit *commit,
@@ -84,12 +140,111 @@ const libgit2_result libgit2_commit_parent(
 const libgit2_result libgit2_commit_parentcount(
 		const git_commit *commit);
 
+const libgit2_result libgit2_commit_tree(
+		git_tree **tree_out,
+		const git_commit *commit);
+
+// config.h
+
+const libgit2_result libgit2_config_add_file_ondisk(
+		git_config *cfg,
+		const char *path,
+		git_config_level_t level,
+		int force);
+
+const libgit2_result libgit2_config_delete_entry(
+		git_config *cfg,
+		const char *name);
+
+const libgit2_result libgit2_config_delete_multivar(
+		git_config *cfg,
+		const char *name,
+		const char *regexp);
+
+const libgit2_result libgit2_config_get_bool(
+		int *out,
+		const git_config *cfg,
+		const char *name);
+
+const libgit2_result libgit2_config_get_entry(
+		const git_config_entry **out,
+		const git_config *cfg,
+		const char *name);
+
+const libgit2_result libgit2_config_get_int64(
+		int64_t *out,
+		const git_config *cfg,
+		const char *name);
+
+const libgit2_result libgit2_config_iterator_glob_new(
+		git_config_iterator **out,
+		const git_config *cfg,
+		const char *regexp);
+
+const libgit2_result libgit2_config_multivar_iterator_new(
+		git_config_iterator **out,
+		const git_config *cfg,
+		const char *name,
+		const char *regexp);
+
+const libgit2_result libgit2_config_next(
+		git_config_entry **entry,
+		git_config_iterator *iter);
+
+const libgit2_result libgit2_config_open_default(
+		git_config **out);
+
+const libgit2_result libgit2_config_open_level(
+		git_config **out,
+		const git_config *parent,
+		git_config_level_t level);
+
+const libgit2_result libgit2_config_open_ondisk(
+		git_config **out,
+		const char *path);
+
+const libgit2_result libgit2_config_set_bool(
+		git_config *cfg,
+		const char *name,
+		int value);
+
+const libgit2_result libgit2_config_set_int64(
+		git_config *cfg,
+		const char *name,
+		int64_t value);
+
+const libgit2_result libgit2_config_set_multivar(
+		git_config *cfg,
+		const char *name,
+		const char *regexp,
+		const char *value);
+
+const libgit2_result libgit2_config_set_string(
+		git_config *cfg,
+		const char *name,
+		const char *value);
+
+const libgit2_result libgit2_config_snapshot(
+		git_config **out,
+		git_config *config);
+
 // index.h
 
 const libgit2_result libgit2_index_add_bypath(
 		git_index *index,
 		const char *path);
 
+const libgit2_result libgit2_index_new(
+		git_index **out);
+
+const libgit2_result libgit2_index_open(
+		git_index **out,
+		const char *index_path);
+
+const libgit2_result libgit2_index_read_tree(
+		git_index *index,
+		const git_tree *tree);
+
 const libgit2_result libgit2_index_write(
 		git_index *index);
 
@@ -97,6 +252,44 @@ const libgit2_result libgit2_index_write_tree(
 		git_oid *out,
 		git_index *index);
 
+// indexer.h
+
+const libgit2_result libgit2_indexer_append(
+		git_indexer *idx,
+		const void *data,
+		size_t size,
+		git_transfer_progress *stats);
+
+const libgit2_result libgit2_indexer_commit(
+		git_indexer *idx,
+		git_transfer_progress *stats);
+
+const libgit2_result libgit2_indexer_new(
+		git_indexer **out,
+		const char *path,
+		unsigned int mode,
+		git_odb *odb,
+		git_transfer_progress_cb progress_cb,
+		void *progress_cb_payload);
+
+// mempack.h
+
+const libgit2_result libgit2_mempack_dump(
+		git_buf *pack,
+		git_repository *repo,
+		git_odb_backend *backend);
+
+const libgit2_result libgit2_mempack_new(
+		git_odb_backend **out);
+
+// merge.h
+
+const libgit2_result libgit2_merge_bases(
+		git_oidarray *out,
+		git_repository *repo,
+		const git_oid *one,
+		const git_oid *two);
+
 // message.h
 
 const libgit2_result libgit2_message_prettify(
@@ -107,12 +300,204 @@ const libgit2_result libgit2_message_prettify(
 
 // object.h
 
+const libgit2_result libgit2_object_dup(
+		git_object **dest,
+		git_object *source);
+
+const libgit2_result libgit2_object_lookup(
+		git_object **object,
+		git_repository *repo,
+		const git_oid *id,
+		git_otype type);
+
+const libgit2_result libgit2_object_lookup_prefix(
+		git_object **object_out,
+		git_repository *repo,
+		const git_oid *id,
+		size_t len,
+		git_otype type);
+
+const libgit2_result libgit2_object_peel(
+		git_object **peeled,
+		const git_object *object,
+		git_otype target_type);
+
 const libgit2_result libgit2_object_short_id(
 		git_buf *out,
 		const git_object *obj);
 
+// odb.h
+
+const libgit2_result libgit2_odb_add_backend(
+		git_odb *odb,
+		git_odb_backend *backend,
+		int priority);
+
+const libgit2_result libgit2_odb_add_disk_alternate(
+		git_odb *odb,
+		const char *path);
+
+const libgit2_result libgit2_odb_exists_prefix(
+		git_oid *out,
+		git_odb *db,
+		const git_oid *short_id,
+		size_t len);
+
+const libgit2_result libgit2_odb_foreach(
+		git_odb *db,
+		void *payload);
+
+const libgit2_result libgit2_odb_new(
+		git_odb **out);
+
+const libgit2_result libgit2_odb_open(
+		git_odb **out,
+		const char *objects_dir);
+
+const libgit2_result libgit2_odb_open_wstream(
+		git_odb_stream **out,
+		git_odb *db,
+		size_t size,
+		git_otype type);
+
+const libgit2_result libgit2_odb_read(
+		git_odb_object **out,
+		git_odb *db,
+		const git_oid *id);
+
+const libgit2_result libgit2_odb_read_header(
+		size_t *len_out,
+		git_otype *type_out,
+		git_odb *db,
+		const git_oid *id);
+
+const libgit2_result libgit2_odb_refresh(
+		git_odb *db);
+
+const libgit2_result libgit2_odb_stream_finalize_write(
+		git_oid *out,
+		git_odb_stream *stream);
+
+const libgit2_result libgit2_odb_stream_write(
+		git_odb_stream *stream,
+		const char *buffer,
+		size_t len);
+
+const libgit2_result libgit2_odb_write(
+		git_oid *out,
+		git_odb *odb,
+		const void *data,
+		size_t len,
+		git_otype type);
+
+// odb_backend.h
+
+git_odb_backend *libgit2_odb_backend_new(
+		void *handle);
+
+void libgit2_odb_backend_free(
+		git_odb_backend *backend);
+
+int libgit2_odb_foreach_cb_call(
+		git_odb_foreach_cb cb,
+		const git_oid *id,
+		void *payload);
+
+// oid.h
+
+const libgit2_result libgit2_oid_fromstr(
+		git_oid *out,
+		const char *str);
+
+const libgit2_result libgit2_oid_fromstrn(
+		git_oid *out,
+		const char *str,
+		size_t length);
+
+// refdb.h
+
+const libgit2_result libgit2_refdb_new(
+		git_refdb **out,
+		git_repository *repo);
+
+const libgit2_result libgit2_refdb_open(
+		git_refdb **out,
+		git_repository *repo);
+
+// refdb_backend.h
+
+const libgit2_result libgit2_refdb_backend_fs(
+		git_refdb_backend **out,
+		git_repository *repo);
+
+const libgit2_result libgit2_refdb_set_backend(
+		git_refdb *refdb,
+		git_refdb_backend *backend);
+
+git_refdb_backend *libgit2_refdb_backend_new(
+		void *handle);
+
+void libgit2_refdb_backend_free(
+		git_refdb_backend *backend);
+
+git_refdb_backend *libgit2_worktree_refdb_new(
+		git_refdb_backend *common,
+		git_refdb_backend *worktree);
+
+git_reference_iterator *libgit2_reference_iterator_new(
+		void *handle);
+
+// reflog.h
+
+const libgit2_result libgit2_reflog_append(
+		git_reflog *reflog,
+		const git_oid *id,
+		const git_signature *committer,
+		const char *msg);
+
+// refs.h
+
+const libgit2_result libgit2_reference_iterator_glob_new(
+		git_reference_iterator **out,
+		git_repository *repo,
+		const char *glob);
+
+const libgit2_result libgit2_reference_lookup(
+		git_reference **out,
+		git_repository *repo,
+		const char *name);
+
+const libgit2_result libgit2_reference_name_to_id(
+		git_oid *out,
+		git_repository *repo,
+		const char *name);
+
+const libgit2_result libgit2_reference_next(
+		git_reference **out,
+		git_reference_iterator *iter);
+
+const libgit2_result libgit2_reference_peel(
+		git_object **out,
+		git_reference *ref,
+		git_otype type);
+
 // repository.h
 
+const libgit2_result libgit2_repository_config(
+		git_config **out,
+		git_repository *repo);
+
+const libgit2_result libgit2_repository_detach_head(
+		git_repository *repo,
+		const git_signature *signature,
+		const char *reflog_message);
+
+const libgit2_result libgit2_repository_discover(
+		git_buf *out,
+		const char *start_path,
+		int across_fs,
+		const char *ceiling_dirs);
+
 const libgit2_result libgit2_repository_head(
 		git_reference **out,
 		git_repository *repo);
@@ -129,12 +514,87 @@ const libgit2_result libgit2_repository_init(
 		const char *path,
 		unsigned int is_bare);
 
+const libgit2_result libgit2_repository_init_ext(
+		git_repository **out,
+		const char *repo_path,
+		git_repository_init_options *opts);
+
+const libgit2_result libgit2_repository_init_init_options(
+		git_repository_init_options *opts,
+		unsigned int version);
+
+const libgit2_result libgit2_repository_message(
+		git_buf *out,
+		git_repository *repo);
+
+const libgit2_result libgit2_repository_message_remove(
+		git_repository *repo);
+
+const libgit2_result libgit2_repository_new(
+		git_repository **out);
+
+const libgit2_result libgit2_repository_odb(
+		git_odb **out,
+		git_repository *repo);
+
 const libgit2_result libgit2_repository_open(
 		git_repository **out,
 		const char *path);
 
+const libgit2_result libgit2_repository_open_ext(
+		git_repository **out,
+		const char *path,
+		unsigned int flags,
+		const char *ceiling_dirs);
+
+const libgit2_result libgit2_repository_set_head(
+		git_repository *repo,
+		const char *refname,
+		const git_signature *signature,
+		const char *log_message);
+
+const libgit2_result libgit2_repository_set_head_detached(
+		git_repository *repo,
+		const git_oid *commitish,
+		const git_signature *signature,
+		const char *log_message);
+
+const libgit2_result libgit2_repository_set_namespace(
+		git_repository *repo,
+		const char *nmspace);
+
+const libgit2_result libgit2_repository_set_workdir(
+		git_repository *repo,
+		const char *workdir,
+		int update_gitlink);
+
+const libgit2_result libgit2_repository_state_cleanup(
+		git_repository *repo);
+
+// revparse.h
+
+const libgit2_result libgit2_revparse(
+		git_revspec *revspec,
+		git_repository *repo,
+		const char *spec);
+
+const libgit2_result libgit2_revparse_ext(
+		git_object **object_out,
+		git_reference **reference_out,
+		git_repository *repo,
+		const char *spec);
+
+const libgit2_result libgit2_revparse_single(
+		git_object **out,
+		git_repository *repo,
+		const char *spec);
+
 // revwalk.h
 
+const libgit2_result libgit2_revwalk_hide(
+		git_revwalk *walk,
+		const git_oid *commit_id);
+
 const libgit2_result libgit2_revwalk_new(
 		git_revwalk **out,
 		git_repository *repo);
@@ -143,6 +603,10 @@ const libgit2_result libgit2_revwalk_next(
 		git_oid *out,
 		git_revwalk *walk);
 
+const libgit2_result libgit2_revwalk_push(
+		git_revwalk *walk,
+		const git_oid *id);
+
 const libgit2_result libgit2_revwalk_push_head(
 		git_revwalk *walk);
 
@@ -156,11 +620,78 @@ const libgit2_result libgit2_signature_dup(
 		git_signature **dest,
 		const git_signature *sig);
 
+const libgit2_result libgit2_signature_new(
+		git_signature **out,
+		const char *name,
+		const char *email,
+		git_time_t time,
+		int offset);
+
+// tag.h
+
+const libgit2_result libgit2_tag_create(
+		git_oid *oid,
+		git_repository *repo,
+		const char *tag_name,
+		const git_object *target,
+		const git_signature *tagger,
+		const char *message,
+		int force);
+
+const libgit2_result libgit2_tag_create_lightweight(
+		git_oid *oid,
+		git_repository *repo,
+		const char *tag_name,
+		const git_object *target,
+		int force);
+
+const libgit2_result libgit2_tag_delete(
+		git_repository *repo,
+		const char *tag_name);
+
+const libgit2_result libgit2_tag_target(
+		git_object **target_out,
+		const git_tag *tag);
+
 // tree.h
 
+const libgit2_result libgit2_tree_entry_bypath(
+		git_tree_entry **out,
+		const git_tree *root,
+		const char *path);
+
 const libgit2_result libgit2_tree_lookup(
 		git_tree **out,
 		git_repository *repo,
 		const git_oid *id);
 
+const libgit2_result libgit2_tree_walk(
+		const git_tree *tree,
+		git_treewalk_mode mode,
+		void *payload);
+
+const libgit2_result libgit2_treebuilder_create(
+		git_treebuilder **out,
+		git_repository *repo,
+		const git_tree *source);
+
+void libgit2_treebuilder_filter(
+		git_treebuilder *bld,
+		void *payload);
+
+const libgit2_result libgit2_treebuilder_insert(
+		const git_tree_entry **out,
+		git_treebuilder *bld,
+		const char *filename,
+		const git_oid *id,
+		git_filemode_t filemode);
+
+const libgit2_result libgit2_treebuilder_remove(
+		git_treebuilder *bld,
+		const char *filename);
+
+const libgit2_result libgit2_treebuilder_write(
+		git_oid *id,
+		git_treebuilder *bld);
+
 #endif
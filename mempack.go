@@ -0,0 +1,164 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"path/filepath"
+	"unsafe"
+)
+
+// mempackPriority is above the priority of the loose and packed backends, so
+// that all new objects are written to the mempack.
+const mempackPriority = 1000
+
+// Mempack is an in-memory object backend attached to a repository. Objects
+// written to the repository are kept in memory instead of on disk, while
+// existing objects are still read from disk. Refs, the index and config are
+// unaffected.
+type Mempack struct {
+	repo Repository
+	odb  *gitODB
+
+	// owned by odb
+	backend *C.git_odb_backend
+}
+
+// NewMempack creates an in-memory object backend and adds it to the object
+// database of repo. Use NewMempackRepository for a repository without any
+// on-disk storage.
+func NewMempack(repo Repository) (*Mempack, error) {
+	if err := repo.acquire(); err != nil {
+		return nil, err
+	}
+	defer repo.release()
+
+	odb, err := gitRepositoryODB(repo.gitRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := gitMempackNew()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := gitODBAddBackend(odb, backend, mempackPriority); err != nil {
+		C.libgit2_odb_backend_free(backend)
+		return nil, err
+	}
+
+	return &Mempack{repo: repo, odb: odb, backend: backend}, nil
+}
+
+// NewMempackRepository creates a repository held entirely in memory. Objects
+// are written to the returned mempack, references are kept in memory with
+// HEAD pointing at the unborn refs/heads/master, and the index starts empty.
+// The config is a read-only snapshot of the global, XDG and system config
+// files. Nothing is written to disk unless the mempack is written to another
+// repository with WriteTo.
+func NewMempackRepository() (*Repository, *Mempack, error) {
+	r, err := gitRepositoryNew()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	odb, err := gitODBNew()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backend, err := gitMempackNew()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := gitODBAddBackend(odb, backend, mempackPriority); err != nil {
+		C.libgit2_odb_backend_free(backend)
+		return nil, nil, err
+	}
+	gitRepositorySetODB(r, odb)
+
+	idx, err := gitIndexNew()
+	if err != nil {
+		return nil, nil, err
+	}
+	gitRepositorySetIndex(r, idx)
+
+	cfg, err := gitConfigOpenDefault()
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot, err := gitConfigSnapshot(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	gitRepositorySetConfig(r, snapshot)
+
+	repo := Repository{r}
+	if err := setRefBackend(repo, newMemRefBackend()); err != nil {
+		return nil, nil, err
+	}
+
+	return &repo, &Mempack{repo: repo, odb: odb, backend: backend}, nil
+}
+
+// Dump returns a packfile holding every object in the mempack.
+func (m Mempack) Dump() ([]byte, error) {
+	if err := m.repo.acquire(); err != nil {
+		return nil, err
+	}
+	defer m.repo.release()
+
+	return gitMempackDump(m.repo.gitRepository, m.backend)
+}
+
+// Reset removes every object from the mempack.
+func (m Mempack) Reset() {
+	C.git_mempack_reset(m.backend)
+}
+
+// WriteTo writes every object in the mempack to the object database of dst
+// as a packfile, so the objects are kept on disk. The dst repository may be
+// the one the mempack is attached to.
+func (m Mempack) WriteTo(dst Repository) error {
+	pack, err := m.Dump()
+	if err != nil {
+		return err
+	}
+
+	if err := dst.acquire(); err != nil {
+		return err
+	}
+	defer dst.release()
+
+	odb, err := gitRepositoryODB(dst.gitRepository)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(dst.Path(), "objects", "pack")
+	if err := gitIndexerWrite(dir, odb, pack); err != nil {
+		return err
+	}
+	return gitODBRefresh(odb)
+}
+
+func gitMempackDump(repo *gitRepository, backend *C.git_odb_backend) ([]byte, error) {
+	buf := &C.git_buf{}
+	defer C.git_buf_free(buf)
+
+	if err := unwrapErr(C.libgit2_mempack_dump(buf, repo.ptr, backend)); err != nil {
+		return nil, err
+	}
+	return goBytes(unsafe.Pointer(buf.ptr), buf.size), nil
+}
+
+func gitMempackNew() (*C.git_odb_backend, error) {
+	var ptr *C.git_odb_backend
+
+	if err := unwrapErr(C.libgit2_mempack_new(&ptr)); err != nil {
+		return nil, err
+	}
+	return ptr, nil
+}
@@ -0,0 +1,134 @@
+package libgit2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMempack(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mp, err := NewMempack(*repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := 5
+	mustSeedRepoN(t, repo, n)
+
+	if _, err := repo.CreateBranch(rndstr()); err != nil {
+		t.Fatal(err)
+	}
+
+	walk, err := repo.Walk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != n {
+		t.Errorf("want %d commits, got %d", n, len(commits))
+	}
+
+	objects := filepath.Join(repo.Path(), "objects")
+	filepath.Walk(objects, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			t.Errorf("unexpected object file %q", path)
+		}
+		return nil
+	})
+
+	pack, err := mp.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pack) == 0 {
+		t.Error("want packfile data, got none")
+	}
+}
+
+func TestMempackWriteTo(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mp, err := NewMempack(*repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustSeedRepo(t, repo)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := mustInitTestRepo(t)
+	if err := mp.WriteTo(*dst); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := lookupCommit(*dst, *head.target()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMempackRepository(t *testing.T) {
+	repo, mp, err := NewMempackRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	n := 3
+	mustSeedRepoN(t, repo, n)
+
+	if _, err := repo.CreateBranch(rndstr()); err != nil {
+		t.Fatal(err)
+	}
+
+	walker, err := repo.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches, err := walker.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 2, len(branches); want != got {
+		t.Errorf("want %d branches, got %d", want, got)
+	}
+
+	walk, err := repo.Walk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != n {
+		t.Errorf("want %d commits, got %d", n, len(commits))
+	}
+
+	tree, err := commits[0].Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tree.Close()
+
+	dst := mustInitTestRepo(t)
+	if err := mp.WriteTo(*dst); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range commits {
+		if _, err := lookupCommit(*dst, c.ID()); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// errObjectClosed is returned when an object is used after it is closed.
+var errObjectClosed = errors.New("object is closed")
+
+// ObjectType is the type of a git object.
+type ObjectType int
+
+const (
+	// ObjectAny matches an object of any type.
+	ObjectAny ObjectType = C.GIT_OBJ_ANY
+	// ObjectBad is an invalid object type.
+	ObjectBad ObjectType = C.GIT_OBJ_BAD
+	// ObjectCommit is a commit object.
+	ObjectCommit ObjectType = C.GIT_OBJ_COMMIT
+	// ObjectTree is a tree (directory listing) object.
+	ObjectTree ObjectType = C.GIT_OBJ_TREE
+	// ObjectBlob is a file revision object.
+	ObjectBlob ObjectType = C.GIT_OBJ_BLOB
+	// ObjectTag is an annotated tag object.
+	ObjectTag ObjectType = C.GIT_OBJ_TAG
+)
+
+func (t ObjectType) String() string {
+	return C.GoString(C.git_object_type2string(C.git_otype(t)))
+}
+
+// Object is a git object, one of *Commit, *Tree, *Blob or *Tag.
+type Object interface {
+	// ID is the object ID of the object.
+	ID() OID
+	// Type is the kind of the object.
+	Type() ObjectType
+	// Peel follows tags and commits until an object of type t is found.
+	// Commits peel to their tree, and ObjectAny peels tags until a
+	// non-tag object is found.
+	Peel(t ObjectType) (Object, error)
+	// Close releases the object.
+	Close() error
+}
+
+func lookupObject(repo Repository, oid OID, t ObjectType) (Object, error) {
+	obj, err := gitObjectLookup(repo.gitRepository, oid, t)
+	if err != nil {
+		return nil, err
+	}
+	return obj.object()
+}
+
+func lookupObjectPrefix(repo Repository, prefix string) (Object, error) {
+	oid, err := parseOIDPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := gitObjectLookupPrefix(repo.gitRepository, oid, len(prefix), ObjectAny)
+	if isAmbiguous(err) {
+		candidates, err := prefixCandidates(repo.gitRepository, prefix)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &AmbiguousOIDError{Prefix: prefix, Candidates: candidates}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return obj.object()
+}
+
+// maxPrefixCandidates caps the candidates listed by an AmbiguousOIDError.
+const maxPrefixCandidates = 16
+
+// prefixCandidates returns the sorted IDs of every object in the repository
+// starting with the hex prefix. It walks the whole object database, so it is
+// only used to report an ambiguous prefix, and stops after
+// maxPrefixCandidates matches.
+func prefixCandidates(repo *gitRepository, prefix string) ([]OID, error) {
+	odb, err := gitRepositoryODB(repo)
+	if err != nil {
+		return nil, err
+	}
+	defer odb.free()
+
+	prefix = strings.ToLower(prefix)
+
+	var candidates []OID
+	err = odbForeach(odb, func(oid OID) error {
+		if strings.HasPrefix(oid.String(), prefix) {
+			candidates = append(candidates, oid)
+		}
+		if len(candidates) == maxPrefixCandidates {
+			return errStopIteration
+		}
+		return nil
+	})
+	if err != nil && err != errStopIteration {
+		return nil, err
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Compare(candidates[j]) < 0
+	})
+	return candidates, nil
+}
+
+// shortID returns the shortest hex prefix of oid, at least minLen characters
+// long, that matches no other object in the repository. Only the existence
+// of each prefix is checked, no object is read. A missing object is
+// reported as not found, rather than abbreviated.
+func shortID(repo *gitRepository, oid OID, minLen int) (string, error) {
+	if minLen < C.GIT_OID_MINPREFIXLEN {
+		minLen = C.GIT_OID_MINPREFIXLEN
+	}
+	if minLen > C.GIT_OID_HEXSZ {
+		minLen = C.GIT_OID_HEXSZ
+	}
+
+	odb, err := gitRepositoryODB(repo)
+	if err != nil {
+		return "", err
+	}
+	defer odb.free()
+
+	if !gitODBExists(odb, oid) {
+		return "", &gitError{
+			message: fmt.Sprintf("object %s not found", oid),
+			class:   errClassOdb,
+			code:    errNotFound,
+		}
+	}
+
+	for n := minLen; n <= C.GIT_OID_HEXSZ; n++ {
+		err := gitODBExistsPrefix(odb, oid, n)
+		if isAmbiguous(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return oid.String()[:n], nil
+	}
+	return "", fmt.Errorf("object id %s is ambiguous", oid)
+}
+
+func peelObject(ptr *C.git_object, repo *gitRepository, t ObjectType) (Object, error) {
+	if ptr == nil {
+		return nil, errObjectClosed
+	}
+	if err := repo.acquire(); err != nil {
+		return nil, err
+	}
+	defer repo.release()
+
+	obj, err := gitObjectPeel(&gitObject{ptr: ptr, repo: repo}, t)
+	if err != nil {
+		return nil, err
+	}
+	return obj.object()
+}
+
+type gitObject struct {
+	ptr *C.git_object
+
+	repo *gitRepository
+}
+
+func (o *gitObject) init() {
+	runtime.SetFinalizer(o, (*gitObject).free)
+}
+
+func (o *gitObject) free() {
+	runtime.SetFinalizer(o, nil)
+	C.git_object_free(o.ptr)
+	o.ptr = nil
+}
+
+// object converts o to the Go type matching its kind, which takes over the
+// libgit2 object. An object of any other kind is released.
+func (o *gitObject) object() (Object, error) {
+	t := ObjectType(C.git_object_type(o.ptr))
+	switch t {
+	case ObjectCommit, ObjectTree, ObjectBlob, ObjectTag:
+	default:
+		o.free()
+		return nil, fmt.Errorf("unexpected object type %s", t)
+	}
+
+	runtime.SetFinalizer(o, nil)
+	ptr := unsafe.Pointer(o.ptr)
+	o.ptr = nil
+
+	switch t {
+	case ObjectCommit:
+		c := &gitCommit{ptr: (*C.git_commit)(ptr), repo: o.repo}
+		c.init()
+		return &Commit{c}, nil
+	case ObjectTree:
+		t := &gitTree{ptr: (*C.git_tree)(ptr), repo: o.repo}
+		t.init()
+		return &Tree{t}, nil
+	case ObjectBlob:
+		b := &gitBlob{ptr: (*C.git_blob)(ptr), repo: o.repo}
+		b.init()
+		return &Blob{b}, nil
+	default:
+		t := &gitTag{ptr: (*C.git_tag)(ptr), repo: o.repo}
+		t.init()
+		return &Tag{t}, nil
+	}
+}
+
+func gitObjectID(obj *gitObject) OID {
+	return newOID(C.git_object_id(obj.ptr))
+}
+
+func gitObjectLookup(repo *gitRepository, oid OID, t ObjectType) (*gitObject, error) {
+	o := &gitObject{repo: repo}
+
+	err := unwrapErr(C.libgit2_object_lookup(&o.ptr, repo.ptr, oid.ptr(), C.git_otype(t)))
+	if err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
+
+func gitObjectLookupPrefix(repo *gitRepository, oid OID, n int, t ObjectType) (*gitObject, error) {
+	o := &gitObject{repo: repo}
+
+	err := unwrapErr(C.libgit2_object_lookup_prefix(&o.ptr, repo.ptr, oid.ptr(), C.size_t(n),
+		C.git_otype(t)))
+	if err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
+
+func gitObjectPeel(obj *gitObject, t ObjectType) (*gitObject, error) {
+	o := &gitObject{repo: obj.repo}
+
+	err := unwrapErr(C.libgit2_object_peel(&o.ptr, obj.ptr, C.git_otype(t)))
+	if err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
@@ -0,0 +1,182 @@
+package libgit2
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 2)
+
+	tip, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := repo.Lookup(tip.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, ok := obj.(*Commit)
+	if !ok {
+		t.Fatalf("want *Commit, got %T", obj)
+	}
+	if want, got := tip.String(), commit.ID().String(); want != got {
+		t.Errorf("want commit %s, got %s", want, got)
+	}
+	if want, got := ObjectCommit, obj.Type(); want != got {
+		t.Errorf("want type %s, got %s", want, got)
+	}
+
+	if _, err := repo.LookupCommit(tip.ID()); err != nil {
+		t.Error(err)
+	}
+	if _, err := repo.LookupBlob(tip.ID()); err == nil {
+		t.Error("want error looking up a commit as a blob")
+	}
+
+	tree, err := obj.Peel(ObjectTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := ObjectTree, tree.Type(); want != got {
+		t.Errorf("want type %s, got %s", want, got)
+	}
+
+	obj, err = repo.Lookup(tree.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := obj.(*Tree); !ok {
+		t.Errorf("want *Tree, got %T", obj)
+	}
+	if _, err := repo.LookupTree(tree.ID()); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := tree.Peel(ObjectCommit); err == nil {
+		t.Error("want error peeling a tree to a commit")
+	}
+	if same, err := commit.Peel(ObjectAny); err != nil || same.ID().String() != tip.String() {
+		t.Errorf("want commit %s, got %v (%v)", tip, same, err)
+	}
+
+	for _, obj := range []Object{commit, tree} {
+		if err := obj.Close(); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLookupPrefix(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 1)
+
+	tip, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prefix := range []string{tip.String()[:7], strings.ToUpper(tip.String()[:12]), tip.String()} {
+		obj, err := repo.LookupPrefix(prefix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj.ID() != tip.ID() {
+			t.Errorf("want object %s for prefix %q, got %s", tip, prefix, obj.ID())
+		}
+		obj.Close()
+	}
+
+	for _, prefix := range []string{"", "abc", "zzzzzzz", tip.String() + "0"} {
+		if _, err := repo.LookupPrefix(prefix); err == nil {
+			t.Errorf("want error looking up prefix %q", prefix)
+		}
+	}
+
+	a, b := mustCreateAmbiguousBlobs(t, repo)
+	prefix := a.String()[:4]
+
+	_, err = repo.LookupPrefix(prefix)
+
+	var ambiguous *AmbiguousOIDError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("want *AmbiguousOIDError, got %v", err)
+	}
+	if want, got := prefix, ambiguous.Prefix; want != got {
+		t.Errorf("want prefix %q, got %q", want, got)
+	}
+
+	want := []OID{a, b}
+	if b.Compare(a) < 0 {
+		want = []OID{b, a}
+	}
+	if got := ambiguous.Candidates; !reflect.DeepEqual(want, got) {
+		t.Errorf("want candidates %v, got %v", want, got)
+	}
+}
+
+func TestShortID(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	a, b := mustCreateAmbiguousBlobs(t, repo)
+
+	for _, minLen := range []int{0, 4, 5} {
+		id, err := repo.ShortID(a, minLen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(id) < 5 || len(id) < minLen {
+			t.Errorf("want unique short id of at least %d characters, got %q", minLen, id)
+		}
+		if !strings.HasPrefix(a.String(), id) || strings.HasPrefix(b.String(), id) {
+			t.Errorf("want short id %q to match only %s", id, a)
+		}
+
+		obj, err := repo.LookupPrefix(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if obj.ID() != a {
+			t.Errorf("want object %s for short id %q, got %s", a, id, obj.ID())
+		}
+		obj.Close()
+	}
+
+	if id, err := repo.ShortID(a, 50); err != nil || id != a.String() {
+		t.Errorf("want full id %s, got %q (%v)", a, id, err)
+	}
+
+	var missing OID
+	missing[0] = 0xff
+	if _, err := repo.ShortID(missing, 7); !isNotFound(err) {
+		t.Errorf("want not found error for missing object, got %v", err)
+	}
+}
+
+// mustCreateAmbiguousBlobs creates blobs until two of them share the same 4
+// character abbreviated ID.
+func mustCreateAmbiguousBlobs(t *testing.T, repo *Repository) (OID, OID) {
+	seen := map[string]OID{}
+	for i := 0; i < 1<<16; i++ {
+		oid := mustCreateBlob(t, repo, fmt.Sprintf("blob %d\n", i))
+
+		prefix := oid.String()[:4]
+		if other, ok := seen[prefix]; ok {
+			return other, oid
+		}
+		seen[prefix] = oid
+	}
+	t.Fatal("no ambiguous blobs")
+	return OID{}, OID{}
+}
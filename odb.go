@@ -0,0 +1,342 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+var (
+	errODBClosed       = errors.New("object database is closed")
+	errODBWriterClosed = errors.New("odb writer is closed")
+)
+
+// ODB is the object database of a repository. It reads and writes the raw
+// contents of objects, from every backend of the repository, without
+// parsing them into typed objects.
+type ODB struct {
+	*gitODB
+
+	repo Repository
+}
+
+func repositoryODB(repo Repository) (*ODB, error) {
+	odb, err := gitRepositoryODB(repo.gitRepository)
+	if err != nil {
+		return nil, err
+	}
+	return &ODB{odb, repo}, nil
+}
+
+// Close releases the object database. It may be called more than once.
+func (o ODB) Close() error {
+	o.free()
+	return nil
+}
+
+// Exists reports whether the object database stores an object.
+func (o ODB) Exists(oid OID) bool {
+	if err := o.acquire(); err != nil {
+		return false
+	}
+	defer o.repo.release()
+
+	return gitODBExists(o.gitODB, oid)
+}
+
+// ForEach calls fn for every object, loose or packed, in the object
+// database. It stops at the first error returned by fn and returns that
+// error.
+func (o ODB) ForEach(fn func(OID) error) error {
+	if err := o.acquire(); err != nil {
+		return err
+	}
+	defer o.repo.release()
+
+	return odbForeach(o.gitODB, fn)
+}
+
+// NewWriter returns a writer that streams an object of type t, whose
+// contents are exactly size bytes long, into the object database. The
+// object is stored once the writer is finalized.
+func (o ODB) NewWriter(t ObjectType, size int64) (*ODBWriter, error) {
+	if err := o.acquire(); err != nil {
+		return nil, err
+	}
+	defer o.repo.release()
+
+	stream, err := gitODBOpenWstream(o.gitODB, size, t)
+	if err != nil {
+		return nil, err
+	}
+	return &ODBWriter{stream, o.repo}, nil
+}
+
+// Read returns the type and raw contents of an object.
+func (o ODB) Read(oid OID) (ObjectType, []byte, error) {
+	if err := o.acquire(); err != nil {
+		return ObjectBad, nil, err
+	}
+	defer o.repo.release()
+
+	return gitODBRead(o.gitODB, oid)
+}
+
+// ReadHeader returns the type and size of an object. Loose objects are only
+// partially inflated to read the header.
+func (o ODB) ReadHeader(oid OID) (ObjectType, int64, error) {
+	if err := o.acquire(); err != nil {
+		return ObjectBad, 0, err
+	}
+	defer o.repo.release()
+
+	size, t, err := gitODBReadHeader(o.gitODB, oid)
+	if err != nil {
+		return ObjectBad, 0, err
+	}
+	return t, size, nil
+}
+
+// Write stores the raw contents of an object of type t, and returns the ID
+// of the object.
+func (o ODB) Write(t ObjectType, data []byte) (OID, error) {
+	if err := o.acquire(); err != nil {
+		return OID{}, err
+	}
+	defer o.repo.release()
+
+	return gitODBWrite(o.gitODB, data, t)
+}
+
+// acquire acquires the repository of the object database for the duration
+// of a call, and fails once the object database itself is closed.
+func (o ODB) acquire() error {
+	if o.ptr == nil {
+		return errODBClosed
+	}
+	return o.repo.acquire()
+}
+
+// ODBWriter streams the contents of an object into an object database.
+type ODBWriter struct {
+	*gitODBStream
+
+	repo Repository
+}
+
+// Close releases the writer, discarding the object if it was not finalized.
+// It may be called more than once.
+func (w ODBWriter) Close() error {
+	w.free()
+	return nil
+}
+
+// Finalize stores the object, once exactly the declared size has been
+// written, and returns the ID of the object.
+func (w ODBWriter) Finalize() (OID, error) {
+	if err := w.acquire(); err != nil {
+		return OID{}, err
+	}
+	defer w.repo.release()
+
+	return gitODBStreamFinalizeWrite(w.gitODBStream)
+}
+
+func (w ODBWriter) Write(p []byte) (int, error) {
+	if err := w.acquire(); err != nil {
+		return 0, err
+	}
+	defer w.repo.release()
+
+	if err := gitODBStreamWrite(w.gitODBStream, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// acquire acquires the repository of the writer for the duration of a call,
+// and fails once the writer itself is closed.
+func (w ODBWriter) acquire() error {
+	if w.ptr == nil {
+		return errODBWriterClosed
+	}
+	return w.repo.acquire()
+}
+
+// odbForeach calls fn with the ID of every object in odb, and stops at the
+// first error returned by fn.
+func odbForeach(odb *gitODB, fn func(OID) error) error {
+	fe := &odbForeachFunc{fn: fn}
+
+	handle := pointerHandles.track(fe)
+	defer pointerHandles.untrack(handle)
+
+	err := gitODBForeach(odb, handle)
+	if fe.err != nil {
+		return fe.err
+	}
+	return err
+}
+
+type odbForeachFunc struct {
+	fn  func(OID) error
+	err error
+}
+
+//export libgit2ODBForeach
+func libgit2ODBForeach(handle unsafe.Pointer, id *C.git_oid) C.int {
+	fe := pointerHandles.get(handle).(*odbForeachFunc)
+	if err := fe.fn(newOID(id)); err != nil {
+		fe.err = err
+		return C.GIT_EUSER
+	}
+	return 0
+}
+
+type gitODB struct {
+	ptr *C.git_odb
+}
+
+func (o *gitODB) init() {
+	runtime.SetFinalizer(o, (*gitODB).free)
+}
+
+func (o *gitODB) free() {
+	runtime.SetFinalizer(o, nil)
+	C.git_odb_free(o.ptr)
+	o.ptr = nil
+}
+
+func gitODBAddDiskAlternate(odb *gitODB, path string) error {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	return unwrapErr(C.libgit2_odb_add_disk_alternate(odb.ptr, cpath))
+}
+
+func gitODBAddBackend(odb *gitODB, backend *C.git_odb_backend, priority int) error {
+	return unwrapErr(C.libgit2_odb_add_backend(odb.ptr, backend, C.int(priority)))
+}
+
+func gitODBExists(odb *gitODB, oid OID) bool {
+	return int(C.git_odb_exists(odb.ptr, oid.ptr())) == 1
+}
+
+func gitODBExistsPrefix(odb *gitODB, oid OID, n int) error {
+	return unwrapErr(C.libgit2_odb_exists_prefix(nil, odb.ptr, oid.ptr(), C.size_t(n)))
+}
+
+func gitODBForeach(odb *gitODB, handle unsafe.Pointer) error {
+	return unwrapErr(C.libgit2_odb_foreach(odb.ptr, handle))
+}
+
+func gitODBNew() (*gitODB, error) {
+	o := new(gitODB)
+
+	if err := unwrapErr(C.libgit2_odb_new(&o.ptr)); err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
+
+func gitODBOpen(objectsDir string) (*gitODB, error) {
+	o := new(gitODB)
+
+	cdir := C.CString(objectsDir)
+	defer C.free(unsafe.Pointer(cdir))
+
+	if err := unwrapErr(C.libgit2_odb_open(&o.ptr, cdir)); err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
+
+func gitODBOpenWstream(odb *gitODB, size int64, t ObjectType) (*gitODBStream, error) {
+	s := new(gitODBStream)
+
+	err := unwrapErr(C.libgit2_odb_open_wstream(&s.ptr, odb.ptr, C.size_t(size), C.git_otype(t)))
+	if err != nil {
+		return nil, err
+	}
+	s.init()
+	return s, nil
+}
+
+func gitODBRead(odb *gitODB, oid OID) (ObjectType, []byte, error) {
+	var obj *C.git_odb_object
+
+	if err := unwrapErr(C.libgit2_odb_read(&obj, odb.ptr, oid.ptr())); err != nil {
+		return ObjectBad, nil, err
+	}
+	defer C.git_odb_object_free(obj)
+
+	data := goBytes(C.git_odb_object_data(obj), C.git_odb_object_size(obj))
+	return ObjectType(C.git_odb_object_type(obj)), data, nil
+}
+
+func gitODBReadHeader(odb *gitODB, oid OID) (int64, ObjectType, error) {
+	var (
+		size C.size_t
+		typ  C.git_otype
+	)
+
+	if err := unwrapErr(C.libgit2_odb_read_header(&size, &typ, odb.ptr, oid.ptr())); err != nil {
+		return 0, ObjectBad, err
+	}
+	return int64(size), ObjectType(typ), nil
+}
+
+func gitODBRefresh(odb *gitODB) error {
+	return unwrapErr(C.libgit2_odb_refresh(odb.ptr))
+}
+
+func gitODBWrite(odb *gitODB, data []byte, t ObjectType) (OID, error) {
+	var oid OID
+
+	var buf unsafe.Pointer
+	if len(data) > 0 {
+		buf = unsafe.Pointer(&data[0])
+	}
+
+	err := unwrapErr(C.libgit2_odb_write(oid.ptr(), odb.ptr, buf, C.size_t(len(data)),
+		C.git_otype(t)))
+	if err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+type gitODBStream struct {
+	ptr *C.git_odb_stream
+}
+
+func (s *gitODBStream) init() {
+	runtime.SetFinalizer(s, (*gitODBStream).free)
+}
+
+func (s *gitODBStream) free() {
+	runtime.SetFinalizer(s, nil)
+	C.git_odb_stream_free(s.ptr)
+	s.ptr = nil
+}
+
+func gitODBStreamFinalizeWrite(stream *gitODBStream) (OID, error) {
+	var oid OID
+	if err := unwrapErr(C.libgit2_odb_stream_finalize_write(oid.ptr(), stream.ptr)); err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+func gitODBStreamWrite(stream *gitODBStream, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return unwrapErr(C.libgit2_odb_stream_write(stream.ptr, (*C.char)(unsafe.Pointer(&data[0])),
+		C.size_t(len(data))))
+}
@@ -0,0 +1,193 @@
+package libgit2
+
+/*
+#include <string.h>
+#include "libgit2.h"
+*/
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+var errStopIteration = errors.New("iteration stopped")
+
+// ObjectBackend is a storage backend for git objects. Backends are added to
+// a repository with AddObjectBackend, and are called by libgit2 whenever
+// objects are read from or written to the repository.
+type ObjectBackend interface {
+	// Read returns the type and raw contents of an object, or ErrNotFound.
+	Read(oid OID) (ObjectType, []byte, error)
+	// ReadHeader returns the type and size of an object, or ErrNotFound.
+	ReadHeader(oid OID) (ObjectType, int64, error)
+	// Write stores the raw contents of an object. The oid is already
+	// computed from the type and data.
+	Write(oid OID, t ObjectType, data []byte) error
+	// Exists reports whether the backend stores an object.
+	Exists(oid OID) bool
+	// ForEach calls fn for every object in the backend, stopping at the
+	// first error returned by fn and returning that error.
+	ForEach(fn func(OID) error) error
+	// Refresh reloads the backend's view of the objects it stores, if
+	// objects can be added to it by other processes.
+	Refresh() error
+}
+
+func addObjectBackend(repo Repository, backend ObjectBackend, priority int) error {
+	odb, err := gitRepositoryODB(repo.gitRepository)
+	if err != nil {
+		return err
+	}
+
+	handle := pointerHandles.track(backend)
+	ptr := C.libgit2_odb_backend_new(handle)
+	if ptr == nil {
+		pointerHandles.untrack(handle)
+		return errors.New("out of memory")
+	}
+
+	if err := gitODBAddBackend(odb, ptr, priority); err != nil {
+		C.libgit2_odb_backend_free(ptr)
+		return err
+	}
+	return nil
+}
+
+func objectBackend(handle unsafe.Pointer) ObjectBackend {
+	return pointerHandles.get(handle).(ObjectBackend)
+}
+
+// readObject copies the contents of an object into a buffer allocated for
+// libgit2.
+func readObject(backend *C.git_odb_backend, data []byte, dataP *unsafe.Pointer,
+	lenP *C.size_t) C.int {
+
+	buf := C.git_odb_backend_malloc(backend, C.size_t(len(data)))
+	if buf == nil {
+		return C.GIT_ERROR
+	}
+	if len(data) > 0 {
+		C.memcpy(buf, unsafe.Pointer(&data[0]), C.size_t(len(data)))
+	}
+
+	*dataP = buf
+	*lenP = C.size_t(len(data))
+	return 0
+}
+
+//export libgit2ObjectBackendExists
+func libgit2ObjectBackendExists(handle unsafe.Pointer, oid *C.git_oid) C.int {
+	return cbool(objectBackend(handle).Exists(newOID(oid)))
+}
+
+//export libgit2ObjectBackendForeach
+func libgit2ObjectBackendForeach(handle unsafe.Pointer, cb C.git_odb_foreach_cb,
+	payload unsafe.Pointer) C.int {
+
+	var code C.int
+	err := objectBackend(handle).ForEach(func(oid OID) error {
+		if code = C.libgit2_odb_foreach_cb_call(cb, oid.ptr(), payload); code != 0 {
+			return errStopIteration
+		}
+		return nil
+	})
+	if code != 0 {
+		return code
+	}
+	if err != nil {
+		return backendError(errClassOdb, err)
+	}
+	return 0
+}
+
+//export libgit2ObjectBackendFree
+func libgit2ObjectBackendFree(handle unsafe.Pointer) {
+	pointerHandles.untrack(handle)
+}
+
+//export libgit2ObjectBackendRead
+func libgit2ObjectBackendRead(handle unsafe.Pointer, dataP *unsafe.Pointer,
+	lenP *C.size_t, typeP *C.git_otype, backend *C.git_odb_backend,
+	oid *C.git_oid) C.int {
+
+	t, data, err := objectBackend(handle).Read(newOID(oid))
+	if err != nil {
+		return backendError(errClassOdb, err)
+	}
+
+	*typeP = C.git_otype(t)
+	return readObject(backend, data, dataP, lenP)
+}
+
+//export libgit2ObjectBackendReadHeader
+func libgit2ObjectBackendReadHeader(handle unsafe.Pointer, lenP *C.size_t,
+	typeP *C.git_otype, oid *C.git_oid) C.int {
+
+	t, size, err := objectBackend(handle).ReadHeader(newOID(oid))
+	if err != nil {
+		return backendError(errClassOdb, err)
+	}
+
+	*typeP = C.git_otype(t)
+	*lenP = C.size_t(size)
+	return 0
+}
+
+//export libgit2ObjectBackendReadPrefix
+func libgit2ObjectBackendReadPrefix(handle unsafe.Pointer, outOID *C.git_oid,
+	dataP *unsafe.Pointer, lenP *C.size_t, typeP *C.git_otype,
+	backend *C.git_odb_backend, shortOID *C.git_oid, n C.size_t) C.int {
+
+	b := objectBackend(handle)
+
+	var match *OID
+	err := b.ForEach(func(oid OID) error {
+		if C.git_oid_ncmp(oid.ptr(), shortOID, n) != 0 {
+			return nil
+		}
+		if match != nil && *match != oid {
+			return errStopIteration
+		}
+		match = &oid
+		return nil
+	})
+	if err == errStopIteration {
+		return C.GIT_EAMBIGUOUS
+	}
+	if err != nil {
+		return backendError(errClassOdb, err)
+	}
+	if match == nil {
+		return C.GIT_ENOTFOUND
+	}
+
+	t, data, err := b.Read(*match)
+	if err != nil {
+		return backendError(errClassOdb, err)
+	}
+
+	C.git_oid_cpy(outOID, match.ptr())
+	*typeP = C.git_otype(t)
+	return readObject(backend, data, dataP, lenP)
+}
+
+//export libgit2ObjectBackendRefresh
+func libgit2ObjectBackendRefresh(handle unsafe.Pointer) C.int {
+	if err := objectBackend(handle).Refresh(); err != nil {
+		return backendError(errClassOdb, err)
+	}
+	return 0
+}
+
+//export libgit2ObjectBackendWrite
+func libgit2ObjectBackendWrite(handle unsafe.Pointer, oid *C.git_oid,
+	data unsafe.Pointer, size C.size_t, t C.git_otype) C.int {
+
+	buf := goBytes(data, size)
+	err := objectBackend(handle).Write(newOID(oid), ObjectType(t), buf)
+	if err != nil {
+		return backendError(errClassOdb, err)
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+package libgit2
+
+import (
+	"sync"
+	"testing"
+)
+
+type mapObject struct {
+	t    ObjectType
+	data []byte
+}
+
+type mapBackend struct {
+	sync.Mutex
+
+	objects map[string]mapObject
+	order   []OID
+}
+
+func newMapBackend() *mapBackend {
+	return &mapBackend{objects: map[string]mapObject{}}
+}
+
+func (b *mapBackend) Read(oid OID) (ObjectType, []byte, error) {
+	b.Lock()
+	defer b.Unlock()
+
+	obj, ok := b.objects[oid.String()]
+	if !ok {
+		return ObjectBad, nil, ErrNotFound
+	}
+	return obj.t, obj.data, nil
+}
+
+func (b *mapBackend) ReadHeader(oid OID) (ObjectType, int64, error) {
+	t, data, err := b.Read(oid)
+	return t, int64(len(data)), err
+}
+
+func (b *mapBackend) Write(oid OID, t ObjectType, data []byte) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if _, ok := b.objects[oid.String()]; !ok {
+		b.order = append(b.order, oid)
+	}
+	b.objects[oid.String()] = mapObject{t, data}
+	return nil
+}
+
+func (b *mapBackend) Exists(oid OID) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	_, ok := b.objects[oid.String()]
+	return ok
+}
+
+func (b *mapBackend) ForEach(fn func(OID) error) error {
+	b.Lock()
+	order := append([]OID{}, b.order...)
+	b.Unlock()
+
+	for _, oid := range order {
+		if err := fn(oid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *mapBackend) Refresh() error { return nil }
+
+func TestObjectBackend(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	backend := newMapBackend()
+	if err := repo.AddObjectBackend(backend, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	n := 3
+	mustSeedRepoN(t, repo, n)
+
+	for _, oid := range backend.order {
+		if !backend.Exists(oid) {
+			t.Errorf("missing object %s in backend", oid)
+		}
+	}
+	if len(backend.objects) < n {
+		t.Errorf("want at least %d objects in backend, got %d", n, len(backend.objects))
+	}
+
+	walk, err := repo.Walk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != n {
+		t.Errorf("want %d commits, got %d", n, len(commits))
+	}
+}
+
+func TestNewRepositoryObjectBackend(t *testing.T) {
+	src := mustInitTestRepo(t)
+	pushd(t, src.Workdir())
+	defer popd(t)
+
+	backend := newMapBackend()
+	if err := src.AddObjectBackend(backend, 1000); err != nil {
+		t.Fatal(err)
+	}
+	mustSeedRepo(t, src)
+
+	head, err := src.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.AddObjectBackend(backend, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := lookupCommit(*repo, *head.target())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.ID().String() != head.target().String() {
+		t.Errorf("want commit %s, got %s", head.target(), commit.ID())
+	}
+
+	shortID, err := commit.ShortID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shortID) == 0 {
+		t.Error("want short id, got none")
+	}
+}
@@ -0,0 +1,216 @@
+package libgit2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestODB(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	odb, err := repo.ODB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer odb.Close()
+
+	data := []byte("hello, world\n")
+
+	oid, err := odb.Write(ObjectBlob, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "4b5fa63702dd96796042e92787f464e28f09f17d", oid.String(); want != got {
+		t.Errorf("want oid %s, got %s", want, got)
+	}
+	if !odb.Exists(oid) {
+		t.Errorf("want object %s to exist", oid)
+	}
+
+	typ, size, err := odb.ReadHeader(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != ObjectBlob || size != int64(len(data)) {
+		t.Errorf("want %s of %d bytes, got %s of %d bytes", ObjectBlob, len(data), typ, size)
+	}
+
+	typ, raw, err := odb.Read(oid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ != ObjectBlob || !bytes.Equal(data, raw) {
+		t.Errorf("want %s %q, got %s %q", ObjectBlob, data, typ, raw)
+	}
+
+	var missing OID
+	missing[0] = 0xff
+	if odb.Exists(missing) {
+		t.Errorf("want object %s to not exist", missing)
+	}
+	if _, _, err := odb.Read(missing); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+	if _, _, err := odb.ReadHeader(missing); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func TestODBWriter(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	odb, err := repo.ODB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer odb.Close()
+
+	data := bytes.Repeat([]byte(rndstr()), 1000)
+
+	w, err := odb.NewWriter(ObjectBlob, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	for chunk := data; len(chunk) > 0; {
+		n := 4096
+		if n > len(chunk) {
+			n = len(chunk)
+		}
+		if _, err := w.Write(chunk[:n]); err != nil {
+			t.Fatal(err)
+		}
+		chunk = chunk[n:]
+	}
+
+	oid, err := w.Finalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := repo.CreateBlob(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != oid {
+		t.Errorf("want oid %s, got %s", want, oid)
+	}
+
+	short, err := odb.NewWriter(ObjectBlob, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer short.Close()
+
+	if _, err := short.Write(data[:10]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := short.Finalize(); err == nil {
+		t.Error("want error finalizing a short write")
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(data); err != errODBWriterClosed {
+		t.Errorf("want error %q, got %v", errODBWriterClosed, err)
+	}
+	if _, err := w.Finalize(); err != errODBWriterClosed {
+		t.Errorf("want error %q, got %v", errODBWriterClosed, err)
+	}
+}
+
+func TestODBClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	odb, err := repo.ODB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	oid := mustCreateBlob(t, repo, rndstr())
+
+	if err := odb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := odb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if odb.Exists(oid) {
+		t.Errorf("want object %s to not exist in a closed object database", oid)
+	}
+	if _, _, err := odb.Read(oid); err != errODBClosed {
+		t.Errorf("want error %q, got %v", errODBClosed, err)
+	}
+	if _, err := odb.Write(ObjectBlob, []byte(rndstr())); err != errODBClosed {
+		t.Errorf("want error %q, got %v", errODBClosed, err)
+	}
+	if err := odb.ForEach(func(OID) error { return nil }); err != errODBClosed {
+		t.Errorf("want error %q, got %v", errODBClosed, err)
+	}
+}
+
+func TestODBForEach(t *testing.T) {
+	src := mustInitTestRepo(t)
+	pushd(t, src.Workdir())
+	defer popd(t)
+
+	mp, err := NewMempack(*src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustSeedRepoN(t, src, 3)
+
+	walk, err := src.Walk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := mustInitTestRepo(t)
+	if err := mp.WriteTo(*repo); err != nil {
+		t.Fatal(err)
+	}
+	loose := mustCreateBlob(t, repo, rndstr())
+
+	odb, err := repo.ODB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer odb.Close()
+
+	seen := map[OID]bool{}
+	if err := odb.ForEach(func(oid OID) error {
+		seen[oid] = true
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range commits {
+		if !seen[c.ID()] {
+			t.Errorf("want packed commit %s", c)
+		}
+	}
+	if !seen[loose] {
+		t.Errorf("want loose blob %s", loose)
+	}
+
+	n := 0
+	err = odb.ForEach(func(oid OID) error {
+		if n++; n == 2 {
+			return ErrExists
+		}
+		return nil
+	})
+	if err != ErrExists {
+		t.Errorf("want error %v, got %v", ErrExists, err)
+	}
+	if n != 2 {
+		t.Errorf("want walk stopped after %d objects, got %d", 2, n)
+	}
+}
@@ -3,29 +3,123 @@ package libgit2
 //#include "libgit2.h"
 import "C"
 
-// OID is the unique identity of any object (commit, tree, blob, tag).
-type OID struct {
-	*gitOID
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"unsafe"
+)
+
+// OID is the unique identity of any object (commit, tree, blob, tag), the
+// SHA-1 hash of its contents. OIDs are plain values: they can be compared
+// with == and used as map keys. The zero OID does not identify any object.
+type OID [20]byte
+
+// ParseOID parses the full 40 character hex form of an OID.
+func ParseOID(s string) (OID, error) {
+	if len(s) != hex.EncodedLen(len(OID{})) {
+		return OID{}, fmt.Errorf("invalid oid %q: want %d hex characters",
+			s, hex.EncodedLen(len(OID{})))
+	}
+	return gitOIDFromstr(s)
+}
+
+// OIDFromBytes returns the OID held in the raw 20 byte form b.
+func OIDFromBytes(b []byte) (OID, error) {
+	var o OID
+	if len(b) != len(o) {
+		return OID{}, fmt.Errorf("invalid oid: want %d bytes, got %d", len(o), len(b))
+	}
+
+	copy(o[:], b)
+	return o, nil
+}
+
+// Bytes returns the raw 20 byte form of the OID.
+func (o OID) Bytes() []byte {
+	return append([]byte(nil), o[:]...)
+}
+
+// Compare returns an integer comparing two OIDs by their bytes. The result
+// is 0 if o == other, -1 if o < other, and +1 if o > other.
+func (o OID) Compare(other OID) int {
+	return bytes.Compare(o[:], other[:])
+}
+
+// Equal reports whether o and other are the same OID.
+func (o OID) Equal(other OID) bool {
+	return o == other
+}
+
+// IsZero reports whether o is the zero OID.
+func (o OID) IsZero() bool {
+	return o == OID{}
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the OID in hex.
+// OIDs are also encoded as hex strings in JSON.
+func (o OID) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
 }
 
 func (o OID) String() string {
-	return gitOIDTostr(o.gitOID)
+	return hex.EncodeToString(o[:])
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding the hex form
+// of an OID.
+func (o *OID) UnmarshalText(text []byte) error {
+	oid, err := ParseOID(string(text))
+	if err != nil {
+		return err
+	}
+
+	*o = oid
+	return nil
 }
 
-func (o OID) isZero() bool {
-	return gitOIDIszero(o.gitOID)
+// parseOIDPrefix parses an abbreviated hex OID, leaving the bytes after the
+// prefix zeroed.
+func parseOIDPrefix(prefix string) (OID, error) {
+	if len(prefix) < C.GIT_OID_MINPREFIXLEN || len(prefix) > C.GIT_OID_HEXSZ {
+		return OID{}, fmt.Errorf("invalid oid prefix %q: want %d to %d hex characters",
+			prefix, C.GIT_OID_MINPREFIXLEN, C.GIT_OID_HEXSZ)
+	}
+	return gitOIDFromstrn(prefix)
 }
 
-type gitOID struct {
-	ptr *C.git_oid
+// newOID copies an oid owned by libgit2.
+func newOID(src *C.git_oid) OID {
+	var o OID
+	C.git_oid_cpy(o.ptr(), src)
+	return o
 }
 
-func gitOIDIszero(oid *gitOID) bool {
-	return int(C.git_oid_iszero(oid.ptr)) == 1
+// ptr returns the OID as a git_oid, which has the same layout.
+func (o *OID) ptr() *C.git_oid {
+	return (*C.git_oid)(unsafe.Pointer(o))
 }
 
-func gitOIDTostr(oid *gitOID) string {
-	nbuf := C.GIT_OID_HEXSZ + 1
-	buf := make([]C.char, nbuf)
-	return C.GoString(C.git_oid_tostr(&buf[0], C.size_t(nbuf), oid.ptr))
+func gitOIDFromstr(str string) (OID, error) {
+	var o OID
+
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+
+	if err := unwrapErr(C.libgit2_oid_fromstr(o.ptr(), cstr)); err != nil {
+		return OID{}, err
+	}
+	return o, nil
+}
+
+func gitOIDFromstrn(str string) (OID, error) {
+	var o OID
+
+	cstr := C.CString(str)
+	defer C.free(unsafe.Pointer(cstr))
+
+	if err := unwrapErr(C.libgit2_oid_fromstrn(o.ptr(), cstr, C.size_t(len(str)))); err != nil {
+		return OID{}, err
+	}
+	return o, nil
 }
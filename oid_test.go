@@ -0,0 +1,110 @@
+package libgit2
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseOID(t *testing.T) {
+	const hex = "4b5fa63702dd96796042e92787f464e28f09f17d"
+
+	oid, err := ParseOID(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := hex, oid.String(); want != got {
+		t.Errorf("want oid %s, got %s", want, got)
+	}
+	if oid.IsZero() {
+		t.Error("want non-zero oid")
+	}
+
+	for _, s := range []string{"", "4b5fa637", hex + "00", "zz5fa63702dd96796042e92787f464e28f09f17d"} {
+		if _, err := ParseOID(s); err == nil {
+			t.Errorf("want error parsing %q", s)
+		}
+	}
+}
+
+func TestOIDFromBytes(t *testing.T) {
+	raw := bytes.Repeat([]byte{0xab}, 20)
+
+	oid, err := OIDFromBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(raw, oid.Bytes()) {
+		t.Errorf("want bytes %x, got %x", raw, oid.Bytes())
+	}
+
+	oid.Bytes()[0] = 0
+	if oid[0] != 0xab {
+		t.Error("want Bytes to return a copy")
+	}
+
+	if _, err := OIDFromBytes(raw[:19]); err == nil {
+		t.Error("want error for short oid")
+	}
+}
+
+func TestOIDCompare(t *testing.T) {
+	a, err := ParseOID("0000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ParseOID("0000000000000000000000000000000000000002")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !a.Equal(a) || a.Equal(b) {
+		t.Errorf("want %s equal only to itself", a)
+	}
+	if a.Compare(b) != -1 || b.Compare(a) != 1 || a.Compare(a) != 0 {
+		t.Errorf("want %s before %s", a, b)
+	}
+	if !(OID{}).IsZero() {
+		t.Error("want zero oid")
+	}
+
+	seen := map[OID]bool{a: true}
+	if c, _ := ParseOID(a.String()); !seen[c] {
+		t.Errorf("want %s to be found as a map key", c)
+	}
+}
+
+func TestOIDMarshal(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	oid, err := repo.CreateBlob([]byte(rndstr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(map[string]OID{"id": oid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `{"id":"`+oid.String()+`"}`, string(data); want != got {
+		t.Errorf("want json %s, got %s", want, got)
+	}
+
+	var v map[string]OID
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if v["id"] != oid {
+		t.Errorf("want oid %s, got %s", oid, v["id"])
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":"nope"}`), &v); err == nil {
+		t.Error("want error decoding invalid oid")
+	}
+
+	blob, err := repo.LookupBlob(v["id"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob.Close()
+}
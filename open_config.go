@@ -0,0 +1,101 @@
+package libgit2
+
+import (
+	"os"
+	"path/filepath"
+)
+
+type openConfig struct {
+	path string
+
+	flags            repositoryOpenFlag
+	search, noSearch bool
+	ceilingDirs      []string
+
+	fromEnv bool
+
+	workTree, objectDir string
+	alternates          []string
+
+	namespace string
+}
+
+func (c *openConfig) check() error {
+	if c.fromEnv {
+		if dir := os.Getenv("GIT_DIR"); dir != "" {
+			c.path = dir
+			c.noSearch = true
+		} else {
+			c.search = true
+		}
+		if dirs := os.Getenv("GIT_CEILING_DIRECTORIES"); dirs != "" {
+			c.ceilingDirs = filepath.SplitList(dirs)
+		}
+		if c.workTree == "" {
+			c.workTree = os.Getenv("GIT_WORK_TREE")
+		}
+		if c.objectDir == "" {
+			c.objectDir = os.Getenv("GIT_OBJECT_DIRECTORY")
+		}
+		if dirs := os.Getenv("GIT_ALTERNATE_OBJECT_DIRECTORIES"); dirs != "" {
+			c.alternates = filepath.SplitList(dirs)
+		}
+		c.namespace = os.Getenv("GIT_NAMESPACE")
+	}
+
+	if c.path == "" {
+		c.path = "."
+	}
+	if !c.search || c.noSearch {
+		c.flags |= repositoryOpenNoSearch
+	}
+
+	return nil
+}
+
+// OpenOption is an option type for opening a repository.
+type OpenOption func(*openConfig)
+
+// FromEnv honors the GIT_DIR, GIT_WORK_TREE, GIT_OBJECT_DIRECTORY,
+// GIT_ALTERNATE_OBJECT_DIRECTORIES, GIT_CEILING_DIRECTORIES and GIT_NAMESPACE
+// environment variables the same way git does. If GIT_DIR is set, it is
+// opened in place of the directory passed to OpenRepository. Otherwise the
+// parent directories of that directory are searched as with Search, unless
+// NoSearch is given.
+func FromEnv() OpenOption {
+	return func(c *openConfig) {
+		c.fromEnv = true
+	}
+}
+
+// NoSearch only opens the given directory, even if the Search or FromEnv
+// option is also given. This is the default without FromEnv.
+func NoSearch() OpenOption {
+	return func(c *openConfig) {
+		c.noSearch = true
+	}
+}
+
+// OpenBare opens the repository as a bare repository, even if it has a
+// working directory.
+func OpenBare() OpenOption {
+	return func(c *openConfig) {
+		c.flags |= repositoryOpenBare
+	}
+}
+
+// Search also searches the parent directories of the given directory for a
+// repository, stopping at the GIT_CEILING_DIRECTORIES when FromEnv is given.
+func Search() OpenOption {
+	return func(c *openConfig) {
+		c.search = true
+	}
+}
+
+// WorkTree sets the working directory of the opened repository, overriding
+// the one in the repository config.
+func WorkTree(dir string) OpenOption {
+	return func(c *openConfig) {
+		c.workTree = dir
+	}
+}
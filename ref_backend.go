@@ -0,0 +1,446 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// RefRecord is the stored form of a reference, as exchanged with a
+// RefBackend. A record is symbolic when SymbolicTarget is set, otherwise it
+// points directly at Target.
+type RefRecord struct {
+	Name           string
+	Target         OID
+	SymbolicTarget string
+}
+
+// IsSymbolic reports whether the record is a symbolic reference.
+func (r RefRecord) IsSymbolic() bool {
+	return r.SymbolicTarget != ""
+}
+
+// ReflogEntry is an entry of a reference log, recording one update of the
+// reference from Old to New.
+type ReflogEntry struct {
+	Old, New  OID
+	Committer *Signature
+	Message   string
+}
+
+// RefBackend is a storage backend for references. A backend replaces the
+// on-disk reference database of a repository with SetRefBackend, and is
+// called by libgit2 whenever references or their logs are read or updated.
+//
+// Backends report missing references with ErrNotFound, failed
+// compare-and-swap updates with ErrModified, conflicting writes with
+// ErrExists and held locks with ErrLocked.
+//
+// Log entries are passed to the backend with the update they describe. Whole
+// logs are read with ReadLog, for example to resolve revisions such as
+// master@{1}, and written back with WriteLog after entries are appended or
+// dropped.
+type RefBackend interface {
+	// Exists reports whether the backend stores a reference.
+	Exists(name string) (bool, error)
+	// Lookup returns the stored reference, or ErrNotFound.
+	Lookup(name string) (RefRecord, error)
+	// Iterate returns the references whose name matches glob, a shell
+	// pattern where '*' also matches '/'. An empty glob matches all
+	// references.
+	Iterate(glob string) ([]RefRecord, error)
+	// Write stores ref. Unless force is set the reference must not
+	// already exist. When old is not nil, or oldTarget is not empty, the
+	// stored reference must currently point at that value. who and message
+	// describe the update for the reference log; who is nil when no log
+	// entry is wanted.
+	Write(ref RefRecord, force bool, who *Signature, message string, old *OID, oldTarget string) error
+	// Rename moves a reference and its log to newName, returning the
+	// renamed reference.
+	Rename(oldName, newName string, force bool, who *Signature, message string) (RefRecord, error)
+	// Delete removes a reference and its log, with the same
+	// compare-and-swap semantics as Write.
+	Delete(name string, old *OID, oldTarget string) error
+	// Compress optimizes the storage of the references, if supported.
+	Compress() error
+
+	// HasLog reports whether a reference has a log.
+	HasLog(name string) bool
+	// EnsureLog makes sure a reference will have a log on its next update.
+	EnsureLog(name string) error
+	// RenameLog moves the log of a reference.
+	RenameLog(oldName, newName string) error
+	// DeleteLog removes the log of a reference.
+	DeleteLog(name string) error
+	// ReadLog returns the log of a reference, oldest entry first. A
+	// reference without a log has no entries. The old value of each entry
+	// is taken from the entry before it.
+	ReadLog(name string) ([]ReflogEntry, error)
+	// WriteLog replaces the log of a reference with entries, oldest entry
+	// first.
+	WriteLog(name string, entries []ReflogEntry) error
+
+	// Lock takes the lock on a reference for a transaction. The reference
+	// does not need to exist.
+	Lock(name string) error
+	// Unlock releases the lock on a reference. When ref is not nil the
+	// transaction succeeded and ref must be stored, logging the update
+	// with who and message if updateLog is set.
+	Unlock(name string, ref *RefRecord, updateLog bool, who *Signature, message string) error
+}
+
+func setRefBackend(repo Repository, backend RefBackend) error {
+	refdb, err := gitRefdbNew(repo.gitRepository)
+	if err != nil {
+		return err
+	}
+	defer refdb.free()
+
+	handle := pointerHandles.track(backend)
+	ptr := C.libgit2_refdb_backend_new(handle)
+	if ptr == nil {
+		pointerHandles.untrack(handle)
+		return errors.New("out of memory")
+	}
+
+	if err := gitRefdbSetBackend(refdb, ptr); err != nil {
+		C.libgit2_refdb_backend_free(ptr)
+		return err
+	}
+
+	gitRepositorySetRefdb(repo.gitRepository, refdb)
+	repo.customRefdb = true
+	return nil
+}
+
+func refBackend(handle unsafe.Pointer) RefBackend {
+	return pointerHandles.get(handle).(RefBackend)
+}
+
+// newRefRecord copies a reference handed to a backend by libgit2.
+func newRefRecord(ref *C.git_reference) RefRecord {
+	rec := RefRecord{Name: C.GoString(C.git_reference_name(ref))}
+	if C.git_reference_type(ref) == C.GIT_REF_SYMBOLIC {
+		rec.SymbolicTarget = C.GoString(C.git_reference_symbolic_target(ref))
+	} else {
+		rec.Target = newOID(C.git_reference_target(ref))
+	}
+	return rec
+}
+
+// allocReference allocates a reference owned by libgit2 from a record
+// returned by a backend.
+func allocReference(rec RefRecord) *C.git_reference {
+	cname := C.CString(rec.Name)
+	defer C.free(unsafe.Pointer(cname))
+
+	if rec.IsSymbolic() {
+		ctarget := C.CString(rec.SymbolicTarget)
+		defer C.free(unsafe.Pointer(ctarget))
+
+		return C.git_reference__alloc_symbolic(cname, ctarget)
+	}
+	return C.git_reference__alloc(cname, rec.Target.ptr(), nil)
+}
+
+func refOldValue(old *C.git_oid, oldTarget *C.char) (*OID, string) {
+	var oid *OID
+	if old != nil {
+		o := newOID(old)
+		oid = &o
+	}
+	return oid, C.GoString(oldTarget)
+}
+
+func refSignature(who *C.git_signature) (*Signature, error) {
+	if who == nil {
+		return nil, nil
+	}
+	return dupSignature(&gitSignature{ptr: who})
+}
+
+// refIterator holds the references being iterated by libgit2, and the name
+// last returned by next_name, which must stay valid until the next call.
+type refIterator struct {
+	refs  []RefRecord
+	cname *C.char
+}
+
+func (it *refIterator) next() (RefRecord, bool) {
+	if it.cname != nil {
+		C.free(unsafe.Pointer(it.cname))
+		it.cname = nil
+	}
+	if len(it.refs) == 0 {
+		return RefRecord{}, false
+	}
+
+	rec := it.refs[0]
+	it.refs = it.refs[1:]
+	return rec, true
+}
+
+//export libgit2RefBackendExists
+func libgit2RefBackendExists(handle unsafe.Pointer, exists *C.int, name *C.char) C.int {
+	ok, err := refBackend(handle).Exists(C.GoString(name))
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	*exists = cbool(ok)
+	return 0
+}
+
+//export libgit2RefBackendLookup
+func libgit2RefBackendLookup(handle unsafe.Pointer, out **C.git_reference, name *C.char) C.int {
+	rec, err := refBackend(handle).Lookup(C.GoString(name))
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	if *out = allocReference(rec); *out == nil {
+		return C.GIT_ERROR
+	}
+	return 0
+}
+
+//export libgit2RefBackendIterator
+func libgit2RefBackendIterator(handle unsafe.Pointer, out **C.git_reference_iterator, glob *C.char) C.int {
+	refs, err := refBackend(handle).Iterate(C.GoString(glob))
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	iter := C.libgit2_reference_iterator_new(pointerHandles.track(&refIterator{refs: refs}))
+	if iter == nil {
+		return C.GIT_ERROR
+	}
+
+	*out = iter
+	return 0
+}
+
+//export libgit2RefIteratorNext
+func libgit2RefIteratorNext(handle unsafe.Pointer, out **C.git_reference) C.int {
+	rec, ok := pointerHandles.get(handle).(*refIterator).next()
+	if !ok {
+		return C.GIT_ITEROVER
+	}
+
+	if *out = allocReference(rec); *out == nil {
+		return C.GIT_ERROR
+	}
+	return 0
+}
+
+//export libgit2RefIteratorNextName
+func libgit2RefIteratorNextName(handle unsafe.Pointer, out **C.char) C.int {
+	it := pointerHandles.get(handle).(*refIterator)
+
+	rec, ok := it.next()
+	if !ok {
+		return C.GIT_ITEROVER
+	}
+
+	it.cname = C.CString(rec.Name)
+	*out = it.cname
+	return 0
+}
+
+//export libgit2RefIteratorFree
+func libgit2RefIteratorFree(handle unsafe.Pointer) {
+	it := pointerHandles.get(handle).(*refIterator)
+	if it.cname != nil {
+		C.free(unsafe.Pointer(it.cname))
+	}
+	pointerHandles.untrack(handle)
+}
+
+//export libgit2RefBackendWrite
+func libgit2RefBackendWrite(handle unsafe.Pointer, ref *C.git_reference, force C.int,
+	who *C.git_signature, message *C.char, old *C.git_oid, oldTarget *C.char) C.int {
+
+	sig, err := refSignature(who)
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	oldOID, oldName := refOldValue(old, oldTarget)
+	if err := refBackend(handle).Write(newRefRecord(ref), force != 0, sig,
+		C.GoString(message), oldOID, oldName); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendRename
+func libgit2RefBackendRename(handle unsafe.Pointer, out **C.git_reference, oldName,
+	newName *C.char, force C.int, who *C.git_signature, message *C.char) C.int {
+
+	sig, err := refSignature(who)
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	rec, err := refBackend(handle).Rename(C.GoString(oldName), C.GoString(newName),
+		force != 0, sig, C.GoString(message))
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	if *out = allocReference(rec); *out == nil {
+		return C.GIT_ERROR
+	}
+	return 0
+}
+
+//export libgit2RefBackendDel
+func libgit2RefBackendDel(handle unsafe.Pointer, name *C.char, old *C.git_oid,
+	oldTarget *C.char) C.int {
+
+	oldOID, oldName := refOldValue(old, oldTarget)
+	if err := refBackend(handle).Delete(C.GoString(name), oldOID, oldName); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendCompress
+func libgit2RefBackendCompress(handle unsafe.Pointer) C.int {
+	if err := refBackend(handle).Compress(); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendHasLog
+func libgit2RefBackendHasLog(handle unsafe.Pointer, name *C.char) C.int {
+	return cbool(refBackend(handle).HasLog(C.GoString(name)))
+}
+
+//export libgit2RefBackendEnsureLog
+func libgit2RefBackendEnsureLog(handle unsafe.Pointer, name *C.char) C.int {
+	if err := refBackend(handle).EnsureLog(C.GoString(name)); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendReflogRename
+func libgit2RefBackendReflogRename(handle unsafe.Pointer, oldName, newName *C.char) C.int {
+	if err := refBackend(handle).RenameLog(C.GoString(oldName), C.GoString(newName)); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendReflogDelete
+func libgit2RefBackendReflogDelete(handle unsafe.Pointer, name *C.char) C.int {
+	if err := refBackend(handle).DeleteLog(C.GoString(name)); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendReflogRead
+func libgit2RefBackendReflogRead(handle unsafe.Pointer, reflog *C.git_reflog, name *C.char) C.int {
+	entries, err := refBackend(handle).ReadLog(C.GoString(name))
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	for _, entry := range entries {
+		if err := gitReflogAppend(reflog, entry); err != nil {
+			return backendError(errClassReference, err)
+		}
+	}
+	return 0
+}
+
+//export libgit2RefBackendReflogWrite
+func libgit2RefBackendReflogWrite(handle unsafe.Pointer, reflog *C.git_reflog, name *C.char) C.int {
+	entries, err := reflogEntries(reflog)
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	if err := refBackend(handle).WriteLog(C.GoString(name), entries); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendLock
+func libgit2RefBackendLock(handle unsafe.Pointer, name *C.char) C.int {
+	if err := refBackend(handle).Lock(C.GoString(name)); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendUnlock
+func libgit2RefBackendUnlock(handle unsafe.Pointer, name *C.char, success,
+	updateReflog C.int, ref *C.git_reference, who *C.git_signature,
+	message *C.char) C.int {
+
+	sig, err := refSignature(who)
+	if err != nil {
+		return backendError(errClassReference, err)
+	}
+
+	var rec *RefRecord
+	if success != 0 && ref != nil {
+		r := newRefRecord(ref)
+		rec = &r
+	}
+
+	if err := refBackend(handle).Unlock(C.GoString(name), rec, updateReflog != 0,
+		sig, C.GoString(message)); err != nil {
+		return backendError(errClassReference, err)
+	}
+	return 0
+}
+
+//export libgit2RefBackendFree
+func libgit2RefBackendFree(handle unsafe.Pointer) {
+	pointerHandles.untrack(handle)
+}
+
+// reflogEntries copies the entries of a log handed to a backend by libgit2,
+// oldest entry first.
+func reflogEntries(reflog *C.git_reflog) ([]ReflogEntry, error) {
+	n := int(C.git_reflog_entrycount(reflog))
+
+	entries := make([]ReflogEntry, n)
+	for i := range entries {
+		// libgit2 indexes logs from the newest entry
+		entry := C.git_reflog_entry_byindex(reflog, C.size_t(n-1-i))
+
+		sig, err := refSignature(C.git_reflog_entry_committer(entry))
+		if err != nil {
+			return nil, err
+		}
+
+		entries[i] = ReflogEntry{
+			Old:       newOID(C.git_reflog_entry_id_old(entry)),
+			New:       newOID(C.git_reflog_entry_id_new(entry)),
+			Committer: sig,
+			Message:   C.GoString(C.git_reflog_entry_message(entry)),
+		}
+	}
+	return entries, nil
+}
+
+func gitReflogAppend(reflog *C.git_reflog, entry ReflogEntry) error {
+	if entry.Committer == nil {
+		return errors.New("reflog entry without a committer")
+	}
+
+	cmsg := C.CString(entry.Message)
+	defer C.free(unsafe.Pointer(cmsg))
+
+	return unwrapErr(C.libgit2_reflog_append(reflog, entry.New.ptr(),
+		entry.Committer.ptr, cmsg))
+}
@@ -0,0 +1,228 @@
+package libgit2
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// memRefBackend is a RefBackend holding references and their logs in memory.
+// A new backend has an unborn HEAD pointing at refs/heads/master.
+type memRefBackend struct {
+	mu sync.Mutex
+
+	refs   map[string]RefRecord
+	logs   map[string][]ReflogEntry
+	locked map[string]bool
+}
+
+func newMemRefBackend() *memRefBackend {
+	return &memRefBackend{
+		refs: map[string]RefRecord{
+			"HEAD": {Name: "HEAD", SymbolicTarget: "refs/heads/master"},
+		},
+		logs:   map[string][]ReflogEntry{},
+		locked: map[string]bool{},
+	}
+}
+
+func (b *memRefBackend) Exists(name string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, ok := b.refs[name]
+	return ok, nil
+}
+
+func (b *memRefBackend) Lookup(name string) (RefRecord, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ref, ok := b.refs[name]
+	if !ok {
+		return RefRecord{}, ErrNotFound
+	}
+	return ref, nil
+}
+
+func (b *memRefBackend) Iterate(glob string) ([]RefRecord, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pattern := regexp.QuoteMeta(glob)
+	pattern = strings.Replace(pattern, `\*`, ".*", -1)
+	pattern = strings.Replace(pattern, `\?`, ".", -1)
+	re := regexp.MustCompile("^" + pattern + "$")
+
+	var refs []RefRecord
+	for name, ref := range b.refs {
+		if name == "HEAD" || (glob != "" && !re.MatchString(name)) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (b *memRefBackend) Write(ref RefRecord, force bool, who *Signature, message string, old *OID, oldTarget string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cur, ok := b.refs[ref.Name]
+	if ok && !force {
+		return ErrExists
+	}
+	if old != nil && (!ok || cur.IsSymbolic() || cur.Target.String() != old.String()) {
+		return ErrModified
+	}
+	if oldTarget != "" && (!ok || cur.SymbolicTarget != oldTarget) {
+		return ErrModified
+	}
+
+	b.refs[ref.Name] = ref
+	b.log(ref.Name, b.target(cur), b.target(ref), who, message)
+	return nil
+}
+
+func (b *memRefBackend) Rename(oldName, newName string, force bool, who *Signature, message string) (RefRecord, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ref, ok := b.refs[oldName]
+	if !ok {
+		return RefRecord{}, ErrNotFound
+	}
+	if _, ok := b.refs[newName]; ok && !force {
+		return RefRecord{}, ErrExists
+	}
+
+	delete(b.refs, oldName)
+	ref.Name = newName
+	b.refs[newName] = ref
+
+	b.logs[newName] = b.logs[oldName]
+	delete(b.logs, oldName)
+	b.log(newName, b.target(ref), b.target(ref), who, message)
+	return ref, nil
+}
+
+func (b *memRefBackend) Delete(name string, old *OID, oldTarget string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cur, ok := b.refs[name]
+	if !ok {
+		return ErrNotFound
+	}
+	if old != nil && (cur.IsSymbolic() || cur.Target.String() != old.String()) {
+		return ErrModified
+	}
+	if oldTarget != "" && cur.SymbolicTarget != oldTarget {
+		return ErrModified
+	}
+
+	delete(b.refs, name)
+	delete(b.logs, name)
+	return nil
+}
+
+func (b *memRefBackend) Compress() error { return nil }
+
+func (b *memRefBackend) HasLog(name string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, ok := b.logs[name]
+	return ok
+}
+
+func (b *memRefBackend) EnsureLog(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.logs[name]; !ok {
+		b.logs[name] = nil
+	}
+	return nil
+}
+
+func (b *memRefBackend) RenameLog(oldName, newName string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.logs[newName] = b.logs[oldName]
+	delete(b.logs, oldName)
+	return nil
+}
+
+func (b *memRefBackend) DeleteLog(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.logs, name)
+	return nil
+}
+
+func (b *memRefBackend) Lock(name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.locked[name] {
+		return ErrLocked
+	}
+	b.locked[name] = true
+	return nil
+}
+
+func (b *memRefBackend) Unlock(name string, ref *RefRecord, updateLog bool, who *Signature, message string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.locked, name)
+	if ref != nil {
+		old := b.target(b.refs[name])
+		b.refs[name] = *ref
+		if updateLog {
+			b.log(name, old, b.target(*ref), who, message)
+		}
+	}
+	return nil
+}
+
+func (b *memRefBackend) ReadLog(name string) ([]ReflogEntry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]ReflogEntry(nil), b.logs[name]...), nil
+}
+
+func (b *memRefBackend) WriteLog(name string, entries []ReflogEntry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.logs[name] = append([]ReflogEntry(nil), entries...)
+	return nil
+}
+
+// log appends an entry to the log of a reference. Updates without a
+// committer are not logged.
+func (b *memRefBackend) log(name string, oldID, newID OID, who *Signature, message string) {
+	if who == nil {
+		return
+	}
+	b.logs[name] = append(b.logs[name], ReflogEntry{
+		Old:       oldID,
+		New:       newID,
+		Committer: who,
+		Message:   message,
+	})
+}
+
+// target returns the object a reference points at, following a symbolic
+// reference one level.
+func (b *memRefBackend) target(ref RefRecord) OID {
+	if ref.IsSymbolic() {
+		return b.refs[ref.SymbolicTarget].Target
+	}
+	return ref.Target
+}
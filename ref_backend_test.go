@@ -0,0 +1,83 @@
+package libgit2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRefBackend(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	backend := newMemRefBackend()
+	if err := repo.SetRefBackend(backend); err != nil {
+		t.Fatal(err)
+	}
+
+	mustSeedRepoN(t, repo, 2)
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	master, err := backend.Lookup("refs/heads/master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := master.Target.String(), head.target().String(); want != got {
+		t.Errorf("want HEAD %s, got %s", want, got)
+	}
+	if want, got := 2, len(backend.logs["refs/heads/master"]); want != got {
+		t.Errorf("want %d reflog entries, got %d", want, got)
+	}
+
+	obj, _, err := repo.RevParse("master@{1}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev, _, err := repo.RevParse("master~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := prev.ID(), obj.ID(); want != got {
+		t.Errorf("want reflog entry %s, got %s", want, got)
+	}
+
+	name := rndstr()
+	if _, err := repo.CreateBranch(name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateBranch(name); err == nil {
+		t.Errorf("want error creating existing branch %q", name)
+	}
+
+	walker, err := repo.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches, err := walker.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 2, len(branches); want != got {
+		t.Errorf("want %d branches, got %d", want, got)
+	}
+
+	if _, err := os.Stat(filepath.Join(repo.Path(), "refs", "heads", name)); !os.IsNotExist(err) {
+		t.Errorf("want branch %q stored outside of the repository, got %v", name, err)
+	}
+}
+
+func TestRefBackendLookupMissing(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	if err := repo.SetRefBackend(newMemRefBackend()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.LocalBranch(rndstr()); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+import "runtime"
+
+type gitRefdb struct {
+	ptr *C.git_refdb
+}
+
+func (d *gitRefdb) init() {
+	runtime.SetFinalizer(d, (*gitRefdb).free)
+}
+
+func (d *gitRefdb) free() {
+	runtime.SetFinalizer(d, nil)
+	C.git_refdb_free(d.ptr)
+	d.ptr = nil
+}
+
+func gitRefdbNew(repo *gitRepository) (*gitRefdb, error) {
+	d := new(gitRefdb)
+
+	if err := unwrapErr(C.libgit2_refdb_new(&d.ptr, repo.ptr)); err != nil {
+		return nil, err
+	}
+
+	d.init()
+	return d, nil
+}
+
+func gitRefdbOpen(repo *gitRepository) (*gitRefdb, error) {
+	d := new(gitRefdb)
+
+	if err := unwrapErr(C.libgit2_refdb_open(&d.ptr, repo.ptr)); err != nil {
+		return nil, err
+	}
+
+	d.init()
+	return d, nil
+}
+
+func gitRefdbSetBackend(refdb *gitRefdb, backend *C.git_refdb_backend) error {
+	return unwrapErr(C.libgit2_refdb_set_backend(refdb.ptr, backend))
+}
@@ -2,15 +2,39 @@ package libgit2
 
 //#include "libgit2.h"
 import "C"
-import "runtime"
+
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// errReferenceClosed is returned when a reference is used after it is
+// closed.
+var errReferenceClosed = errors.New("reference is closed")
 
 // Reference is the in-memory representation of a reference.
 type Reference struct {
 	*gitReference
 }
 
+// Close releases the reference. It may be called more than once.
+func (r *Reference) Close() error {
+	r.free()
+	return nil
+}
+
+// Name is the full name of the reference, such as refs/heads/master.
+func (r *Reference) Name() string {
+	if r.ptr == nil {
+		return ""
+	}
+	return gitReferenceName(r.gitReference)
+}
+
 func (r *Reference) target() *OID {
-	return &OID{gitReferenceTarget(r.gitReference)}
+	oid := gitReferenceTarget(r.gitReference)
+	return &oid
 }
 
 type gitReference struct {
@@ -24,8 +48,84 @@ func (r *gitReference) init() {
 func (r *gitReference) free() {
 	runtime.SetFinalizer(r, nil)
 	C.git_reference_free(r.ptr)
+	r.ptr = nil
+}
+
+type gitReferenceIterator struct {
+	ptr *C.git_reference_iterator
+}
+
+func (i *gitReferenceIterator) init() {
+	runtime.SetFinalizer(i, (*gitReferenceIterator).free)
+}
+
+func (i *gitReferenceIterator) free() {
+	runtime.SetFinalizer(i, nil)
+	C.git_reference_iterator_free(i.ptr)
+	i.ptr = nil
+}
+
+func (i *gitReferenceIterator) next() (*gitReference, error) {
+	var ptr *C.git_reference
+
+	if err := unwrapErr(C.libgit2_reference_next(&ptr, i.ptr)); err != nil {
+		return nil, err
+	}
+	if ptr == nil {
+		return nil, nil
+	}
+
+	r := &gitReference{ptr}
+	r.init()
+	return r, nil
 }
 
-func gitReferenceTarget(ref *gitReference) *gitOID {
-	return &gitOID{ptr: C.git_reference_target(ref.ptr)}
+func gitReferenceIteratorGlobNew(repo *gitRepository, glob string) (*gitReferenceIterator, error) {
+	i := new(gitReferenceIterator)
+
+	cglob := C.CString(glob)
+	defer C.free(unsafe.Pointer(cglob))
+
+	if err := unwrapErr(C.libgit2_reference_iterator_glob_new(&i.ptr, repo.ptr, cglob)); err != nil {
+		return nil, err
+	}
+	i.init()
+	return i, nil
+}
+
+func gitReferenceName(ref *gitReference) string {
+	return C.GoString(C.git_reference_name(ref.ptr))
+}
+
+// gitReferenceSymbolicTarget returns the name of the reference a symbolic
+// reference points to, or an empty string for a direct reference.
+func gitReferenceSymbolicTarget(ref *gitReference) string {
+	return C.GoString(C.git_reference_symbolic_target(ref.ptr))
+}
+
+func gitReferenceTarget(ref *gitReference) OID {
+	return newOID(C.git_reference_target(ref.ptr))
+}
+
+func gitReferenceLookup(repo *gitRepository, name string) (*gitReference, error) {
+	r := new(gitReference)
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+
+	if err := unwrapErr(C.libgit2_reference_lookup(&r.ptr, repo.ptr, cname)); err != nil {
+		return nil, err
+	}
+	r.init()
+	return r, nil
+}
+
+func gitReferencePeel(ref *gitReference, repo *gitRepository, t ObjectType) (*gitObject, error) {
+	o := &gitObject{repo: repo}
+
+	if err := unwrapErr(C.libgit2_reference_peel(&o.ptr, ref.ptr, C.git_otype(t))); err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
 }
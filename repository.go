@@ -4,15 +4,128 @@ package libgit2
 import "C"
 
 import (
+	"errors"
+	"io"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"unsafe"
 )
 
+var errNamespaceRefdb = errors.New("namespaces are only supported by the on-disk reference database")
+
+type repositoryOpenFlag uint
+
+const (
+	repositoryOpenNoSearch repositoryOpenFlag = C.GIT_REPOSITORY_OPEN_NO_SEARCH
+	repositoryOpenCrossFS  repositoryOpenFlag = C.GIT_REPOSITORY_OPEN_CROSS_FS
+	repositoryOpenBare     repositoryOpenFlag = C.GIT_REPOSITORY_OPEN_BARE
+)
+
+type repositoryInitFlag uint32
+
+const (
+	repositoryInitBare             repositoryInitFlag = C.GIT_REPOSITORY_INIT_BARE
+	repositoryInitMkdir            repositoryInitFlag = C.GIT_REPOSITORY_INIT_MKDIR
+	repositoryInitMkpath           repositoryInitFlag = C.GIT_REPOSITORY_INIT_MKPATH
+	repositoryInitExternalTemplate repositoryInitFlag = C.GIT_REPOSITORY_INIT_EXTERNAL_TEMPLATE
+)
+
+// SharedMode sets the permissions of the files in a repository. Values other
+// than the predefined modes are used as octal permissions, as with the
+// --shared option of git init.
+type SharedMode uint32
+
+const (
+	// SharedUmask uses permissions from the current umask.
+	SharedUmask SharedMode = C.GIT_REPOSITORY_INIT_SHARED_UMASK
+	// SharedGroup makes the repository group writable, and sets the setgid
+	// bit on its directories.
+	SharedGroup SharedMode = C.GIT_REPOSITORY_INIT_SHARED_GROUP
+	// SharedAll makes the repository readable by everyone, and group
+	// writable.
+	SharedAll SharedMode = C.GIT_REPOSITORY_INIT_SHARED_ALL
+)
+
+// RepositoryState is the kind of operation in progress in a repository.
+type RepositoryState int
+
+const (
+	// StateNone means no operation is in progress.
+	StateNone RepositoryState = C.GIT_REPOSITORY_STATE_NONE
+	// StateMerge means a merge is in progress.
+	StateMerge RepositoryState = C.GIT_REPOSITORY_STATE_MERGE
+	// StateRevert means a revert is in progress.
+	StateRevert RepositoryState = C.GIT_REPOSITORY_STATE_REVERT
+	// StateCherrypick means a cherry-pick is in progress.
+	StateCherrypick RepositoryState = C.GIT_REPOSITORY_STATE_CHERRYPICK
+	// StateBisect means a bisect is in progress.
+	StateBisect RepositoryState = C.GIT_REPOSITORY_STATE_BISECT
+	// StateRebase means a rebase is in progress.
+	StateRebase RepositoryState = C.GIT_REPOSITORY_STATE_REBASE
+	// StateRebaseInteractive means an interactive rebase is in progress.
+	StateRebaseInteractive RepositoryState = C.GIT_REPOSITORY_STATE_REBASE_INTERACTIVE
+	// StateRebaseMerge means a merge based rebase is in progress.
+	StateRebaseMerge RepositoryState = C.GIT_REPOSITORY_STATE_REBASE_MERGE
+	// StateApplyMailbox means git am is in progress.
+	StateApplyMailbox RepositoryState = C.GIT_REPOSITORY_STATE_APPLY_MAILBOX
+	// StateApplyMailboxOrRebase means either git am or a rebase is in
+	// progress.
+	StateApplyMailboxOrRebase RepositoryState = C.GIT_REPOSITORY_STATE_APPLY_MAILBOX_OR_REBASE
+)
+
+var repositoryStateNames = map[RepositoryState]string{
+	StateNone:                 "none",
+	StateMerge:                "merge",
+	StateRevert:               "revert",
+	StateCherrypick:           "cherry-pick",
+	StateBisect:               "bisect",
+	StateRebase:               "rebase",
+	StateRebaseInteractive:    "rebase-interactive",
+	StateRebaseMerge:          "rebase-merge",
+	StateApplyMailbox:         "am",
+	StateApplyMailboxOrRebase: "am/rebase",
+}
+
+func (s RepositoryState) String() string {
+	if name, ok := repositoryStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
 // Repository is an on-disk Git repository.
 type Repository struct {
 	*gitRepository
 }
 
+// DiscoverRepository looks for a git repository in start and each of its
+// parent directories, then opens the first one found. It returns the opened
+// repository and the path to its git directory.
+func DiscoverRepository(start string, options ...DiscoverOption) (*Repository, string, error) {
+	config := &discoverConfig{start: start}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(); err != nil {
+		return nil, "", err
+	}
+
+	path, err := gitRepositoryDiscover(config.start, config.acrossFS,
+		config.ceilingDirs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r, err := gitRepositoryOpen(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &Repository{r}, path, nil
+}
+
 // InitBareRepository initializes a are Git repository.
 func InitBareRepository(dir string) (*Repository, error) {
 	r, err := gitInitRepository(dir, true)
@@ -33,9 +146,17 @@ func InitRepository(dir string) (*Repository, error) {
 	return &Repository{r}, nil
 }
 
-// OpenRepository opens a git repository.
-func OpenRepository(dir string) (*Repository, error) {
-	r, err := gitRepositoryOpen(dir)
+// InitRepositoryWith initializes a Git repository with the given options.
+func InitRepositoryWith(dir string, options ...InitOption) (*Repository, error) {
+	config := &initConfig{path: dir}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+
+	r, err := gitRepositoryInitExt(config)
 	if err != nil {
 		return nil, err
 	}
@@ -43,14 +164,125 @@ func OpenRepository(dir string) (*Repository, error) {
 	return &Repository{r}, nil
 }
 
+// NewRepository creates a repository with no on-disk storage and an empty
+// object database. Objects are stored by the backends added with
+// AddObjectBackend.
+func NewRepository() (*Repository, error) {
+	r, err := gitRepositoryNew()
+	if err != nil {
+		return nil, err
+	}
+
+	odb, err := gitODBNew()
+	if err != nil {
+		return nil, err
+	}
+	gitRepositorySetODB(r, odb)
+
+	return &Repository{r}, nil
+}
+
+// OpenFromWorktree opens the repository of a linked worktree. The returned
+// repository uses the working directory, index and HEAD of the worktree, and
+// shares all other references and the objects with the main repository.
+//
+// HEAD and its log are kept in the administrative directory of the worktree.
+// libgit2 itself has no notion of linked worktrees, so state files such as
+// MERGE_HEAD are still read from the common directory, and commits to the
+// checked out branch are logged to the HEAD log of the main worktree.
+func OpenFromWorktree(wt *Worktree) (*Repository, error) {
+	if err := wt.Validate(); err != nil {
+		return nil, err
+	}
+	return openWorktree(wt)
+}
+
+// OpenRepository opens the git repository in dir. The parent directories of
+// dir are only searched for a repository with the Search option, or with
+// FromEnv when GIT_DIR is not set.
+func OpenRepository(dir string, options ...OpenOption) (*Repository, error) {
+	config := &openConfig{path: dir}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+
+	return openRepository(config)
+}
+
+// AddObjectBackend adds a backend to the object database of the repository.
+// Backends with a higher priority are read from first, and new objects are
+// written to the highest priority backend that supports writing.
+func (r Repository) AddObjectBackend(backend ObjectBackend, priority int) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return addObjectBackend(r, backend, priority)
+}
+
+// AddWorktree creates a new worktree with the given name, checked out in
+// path. Nothing is left behind if the worktree cannot be created.
+func (r Repository) AddWorktree(name, path string, options ...WorktreeOption) (*Worktree, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	config := &worktreeConfig{repo: r, name: name, path: path}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.check(); err != nil {
+		return nil, err
+	}
+
+	return addWorktree(config)
+}
+
 // Branches returns a branch walker for all the repository's branches (local
 // and remote).
 func (r Repository) Branches() (*BranchWalker, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	return newBranchWalker(r, branchAll)
 }
 
+// CleanupState removes the metadata of an in-progress operation, such as
+// MERGE_HEAD, MERGE_MSG or the rebase directories, returning the repository to
+// StateNone.
+func (r Repository) CleanupState() error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return gitRepositoryStateCleanup(r.gitRepository)
+}
+
+// Close releases the repository and the files it holds open. Objects read
+// from the repository remain valid and must be closed separately, but
+// operations that need the repository, on it or on its objects, return
+// ErrClosed. Operations already in progress finish before the repository is
+// released. Close may be called more than once.
+func (r Repository) Close() error {
+	r.free()
+	return nil
+}
+
 // Commit creates a new commit in the repository.
 func (r Repository) Commit(options ...CommitOption) (*Commit, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	config := &commitConfig{repo: r}
 	for _, opt := range options {
 		opt(config)
@@ -62,8 +294,58 @@ func (r Repository) Commit(options ...CommitOption) (*Commit, error) {
 	return createCommit(config)
 }
 
+// Config returns the config of the repository, including the global, XDG
+// and system config files.
+func (r Repository) Config() (*Config, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return repositoryConfig(r)
+}
+
+// CreateBlob writes data to the object database as a blob.
+func (r Repository) CreateBlob(data []byte) (OID, error) {
+	if err := r.acquire(); err != nil {
+		return OID{}, err
+	}
+	defer r.release()
+
+	return gitBlobCreateFrombuffer(r.gitRepository, data)
+}
+
+// CreateBlobFromReader writes the contents of rd to the object database as a
+// blob. The contents are streamed into the repository in chunks, so they are
+// never held in memory as a whole.
+func (r Repository) CreateBlobFromReader(rd io.Reader) (OID, error) {
+	if err := r.acquire(); err != nil {
+		return OID{}, err
+	}
+	defer r.release()
+
+	return createBlobFromReader(r.gitRepository, rd)
+}
+
+// CreateBlobFromWorkdir writes a file of the working directory to the object
+// database as a blob, applying the filters configured for the path. The path
+// is relative to the working directory.
+func (r Repository) CreateBlobFromWorkdir(path string) (OID, error) {
+	if err := r.acquire(); err != nil {
+		return OID{}, err
+	}
+	defer r.release()
+
+	return gitBlobCreateFromworkdir(r.gitRepository, path)
+}
+
 // CreateBranch creates a new local branch with the given name and options.
 func (r Repository) CreateBranch(name string, options ...BranchOption) (*Branch, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	config := &branchConfig{repo: r, name: name}
 	for _, opt := range options {
 		opt(config)
@@ -75,14 +357,84 @@ func (r Repository) CreateBranch(name string, options ...BranchOption) (*Branch,
 	return createBranch(config)
 }
 
+// CreateLightweightTag creates a tag reference pointing directly to an
+// object, the HEAD commit unless TagTarget is given.
+func (r Repository) CreateLightweightTag(name string, options ...TagOption) (*TagRef, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	config := &tagConfig{repo: r, name: name}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.checkCreateLightweight(); err != nil {
+		return nil, err
+	}
+
+	return createLightweightTag(config)
+}
+
+// CreateTag creates an annotated tag object with a message, and a tag
+// reference pointing to it. The tag points to the HEAD commit unless
+// TagTarget is given.
+func (r Repository) CreateTag(name, message string, options ...TagOption) (*Tag, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	config := &tagConfig{repo: r, name: name}
+	for _, opt := range options {
+		opt(config)
+	}
+	if err := config.checkCreate(); err != nil {
+		return nil, err
+	}
+
+	return createTag(config, message)
+}
+
 // DefaultSignature returns a new action signature with default user and now
 // timestamp.
 func (r Repository) DefaultSignature() (*Signature, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	return defaultSignature(r)
 }
 
+// DeleteTag deletes a tag reference by its name.
+func (r Repository) DeleteTag(name string) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return gitTagDelete(r.gitRepository, name)
+}
+
+// DetachHead points HEAD directly at the commit it currently resolves to,
+// instead of at a branch.
+func (r Repository) DetachHead(options ...HeadOption) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return detachHead(r, options)
+}
+
 // Head retrieves and resolves the reference pointed at by HEAD.
 func (r Repository) Head() (*Reference, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	ref, err := gitRepositoryHead(r.gitRepository)
 	if err != nil {
 		return nil, err
@@ -92,17 +444,43 @@ func (r Repository) Head() (*Reference, error) {
 
 // Index returns the index file for the repository.
 func (r Repository) Index() (*Index, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	return repositoryIndex(r)
 }
 
 // IsBare returns true if the repository is does not contain a working
 // directory.
 func (r Repository) IsBare() bool {
+	if r.acquire() != nil {
+		return false
+	}
+	defer r.release()
+
 	return gitRepositoryIsBare(r.gitRepository)
 }
 
+// IsShallow reports whether the repository is a shallow clone, with its
+// history cut at the ShallowRoots.
+func (r Repository) IsShallow() bool {
+	if r.acquire() != nil {
+		return false
+	}
+	defer r.release()
+
+	return gitRepositoryIsShallow(r.gitRepository)
+}
+
 // LocalBranch looks up a local branch in the repository by its name.
 func (r Repository) LocalBranch(name string) (*Branch, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	ref, err := gitBranchLookup(r.gitRepository, name, branchLocal)
 	if err != nil {
 		return nil, err
@@ -110,14 +488,321 @@ func (r Repository) LocalBranch(name string) (*Branch, error) {
 	return &Branch{ref, branchLocal, r}, nil
 }
 
+// Lookup looks up an object of any type by its ID, returning a *Commit,
+// *Tree, *Blob or *Tag.
+func (r Repository) Lookup(oid OID) (Object, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return lookupObject(r, oid, ObjectAny)
+}
+
+// LookupBlob looks up a blob by its ID.
+func (r Repository) LookupBlob(oid OID) (*Blob, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	obj, err := lookupObject(r, oid, ObjectBlob)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*Blob), nil
+}
+
+// LookupCommit looks up a commit by its ID.
+func (r Repository) LookupCommit(oid OID) (*Commit, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return lookupCommit(r, oid)
+}
+
+// LookupPrefix looks up an object by an abbreviated hex object ID of at
+// least 4 characters. If the prefix matches more than one object, the error
+// is an *AmbiguousOIDError listing the matching IDs. Collecting them walks
+// the whole object database, which is slow in large repositories.
+func (r Repository) LookupPrefix(prefix string) (Object, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return lookupObjectPrefix(r, prefix)
+}
+
+// LookupTag looks up an annotated tag by its ID.
+func (r Repository) LookupTag(oid OID) (*Tag, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	obj, err := lookupObject(r, oid, ObjectTag)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*Tag), nil
+}
+
+// LookupTagRef looks up a tag reference by the name of the tag.
+func (r Repository) LookupTagRef(name string) (*TagRef, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return lookupTagRef(r, name)
+}
+
+// LookupTree looks up a tree by its ID.
+func (r Repository) LookupTree(oid OID) (*Tree, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return lookupTree(r, oid)
+}
+
+// LookupWorktree looks up a linked worktree of the repository by its name.
+func (r Repository) LookupWorktree(name string) (*Worktree, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return lookupWorktree(r, name)
+}
+
+// MergeMessage returns the prepared commit message of an in-progress
+// operation (MERGE_MSG), such as a merge, revert or cherry-pick.
+func (r Repository) MergeMessage() (string, error) {
+	if err := r.acquire(); err != nil {
+		return "", err
+	}
+	defer r.release()
+
+	return gitRepositoryMessage(r.gitRepository)
+}
+
+// Namespace returns the namespace of the repository's references, or an
+// empty string if none is set.
+func (r Repository) Namespace() string {
+	if r.acquire() != nil {
+		return ""
+	}
+	defer r.release()
+
+	return gitRepositoryGetNamespace(r.gitRepository)
+}
+
+// ODB returns the object database of the repository.
+func (r Repository) ODB() (*ODB, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return repositoryODB(r)
+}
+
 // Path returns the file path the .git directory for normal repositories, or
 // the repository itself for bare repositories.
 func (r Repository) Path() string {
+	if r.acquire() != nil {
+		return ""
+	}
+	defer r.release()
+
 	return gitRepositoryPath(r.gitRepository)
 }
 
+// RemoveMergeMessage removes the prepared commit message (MERGE_MSG) of an
+// in-progress operation.
+func (r Repository) RemoveMergeMessage() error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return gitRepositoryMessageRemove(r.gitRepository)
+}
+
+// RevParse resolves a single revision expression, such as HEAD~3,
+// main^{tree}, v1.2:path/file, @{upstream} or main@{2.days.ago}, to an
+// object. If the expression goes through a reference, such as main in
+// main~2, that reference is also returned, otherwise the reference is nil.
+func (r Repository) RevParse(spec string) (Object, *Reference, error) {
+	if err := r.acquire(); err != nil {
+		return nil, nil, err
+	}
+	defer r.release()
+
+	return revParse(r, spec)
+}
+
+// RevParseRange parses a revision expression that may be a range, such as
+// a..b or a...b. A single revision is returned with only From set. The
+// result can be walked with the Range walker option.
+func (r Repository) RevParseRange(spec string) (*Revspec, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return revParseRange(r, spec)
+}
+
+// SetHead points HEAD at the reference refname, such as "refs/heads/main". If
+// refname is a branch that does not exist yet, HEAD becomes unborn. Other
+// existing references are resolved, and HEAD is detached at their target.
+// The working directory and index are left unchanged.
+func (r Repository) SetHead(refname string, options ...HeadOption) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return setHead(r, refname, options)
+}
+
+// SetHeadDetached points HEAD directly at the commit oid. The working
+// directory and index are left unchanged.
+func (r Repository) SetHeadDetached(oid OID, options ...HeadOption) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return setHeadDetached(r, oid, oid.String(), options)
+}
+
+// SetHeadDetachedFromRevision points HEAD directly at the commit that the
+// revision spec, such as "v1.0" or "main~2", resolves to. The spec is
+// recorded in the reflog entry of HEAD, in place of the commit ID.
+func (r Repository) SetHeadDetachedFromRevision(spec string, options ...HeadOption) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return setHeadDetachedFromRevision(r, spec, options)
+}
+
+// SetNamespace confines the references of the repository, including HEAD, to
+// the refs/namespaces/<ns>/ hierarchy, as with the GIT_NAMESPACE environment
+// variable. Nested namespaces are separated by slashes. An empty namespace
+// restores the top level references.
+//
+// The on-disk reference database is reloaded for the new namespace. Setting
+// a namespace fails once the references are stored elsewhere, as with
+// SetRefBackend, OpenFromWorktree and NewMempackRepository. A namespace that
+// has not been used before has no HEAD, so HEAD cannot be resolved until
+// SetHead creates it.
+func (r Repository) SetNamespace(ns string) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return setNamespace(r.gitRepository, ns)
+}
+
+// SetRefBackend replaces the reference database of the repository with
+// backend. All references, including HEAD, are then read from and written to
+// the backend.
+func (r Repository) SetRefBackend(backend RefBackend) error {
+	if err := r.acquire(); err != nil {
+		return err
+	}
+	defer r.release()
+
+	return setRefBackend(r, backend)
+}
+
+// ShallowRoots returns the commits at which the history of a shallow
+// repository is cut. These commits are treated as root commits, since their
+// parents are not available.
+func (r Repository) ShallowRoots() ([]OID, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return shallowRoots(r.gitRepository)
+}
+
+// ShortID returns the shortest abbreviation of oid, at least minLen hex
+// characters long, that is unique in the repository. The object must exist.
+func (r Repository) ShortID(oid OID, minLen int) (string, error) {
+	if err := r.acquire(); err != nil {
+		return "", err
+	}
+	defer r.release()
+
+	return shortID(r.gitRepository, oid, minLen)
+}
+
+// State returns the kind of operation, if any, in progress in the repository.
+func (r Repository) State() RepositoryState {
+	if r.acquire() != nil {
+		return StateNone
+	}
+	defer r.release()
+
+	return gitRepositoryState(r.gitRepository)
+}
+
+// Tags returns a walker for the repository's tags, both annotated and
+// lightweight, whose name matches pattern. Patterns use shell glob syntax,
+// and an empty pattern matches every tag.
+func (r Repository) Tags(pattern string) (*TagWalker, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return newTagWalker(r, pattern)
+}
+
+// TreeBuilder returns a builder for a new tree, starting with the entries of
+// base, or with no entries if base is nil.
+func (r Repository) TreeBuilder(base *Tree) (*TreeBuilder, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return newTreeBuilder(r, base)
+}
+
+// UpdateTree writes a copy of base with updates applied to it, and returns
+// the new tree. Updates may change entries of nested subtrees, for instance
+// "a/b/c.txt", in which case every tree leading to the entry is rewritten.
+// Subtrees left empty by the updates are removed. A nil base starts from an
+// empty tree.
+func (r Repository) UpdateTree(base *Tree, updates ...TreeUpdate) (*Tree, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return createUpdatedTree(r, base, updates)
+}
+
 // Walk returns an in-progress walk through the commits in the repo.
 func (r Repository) Walk(options ...WalkerOption) (*Walker, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
 	config := &walkerConfig{repo: r}
 	for _, opt := range options {
 		opt(config)
@@ -131,9 +816,97 @@ func (r Repository) Walk(options ...WalkerOption) (*Walker, error) {
 
 // Workdir returns the file path of the working directory for the repository.
 func (r Repository) Workdir() string {
+	if r.acquire() != nil {
+		return ""
+	}
+	defer r.release()
+
 	return gitRepositoryWorkdir(r.gitRepository)
 }
 
+// Worktrees returns the linked worktrees of the repository. The main working
+// directory is not included.
+func (r Repository) Worktrees() ([]*Worktree, error) {
+	if err := r.acquire(); err != nil {
+		return nil, err
+	}
+	defer r.release()
+
+	return repositoryWorktrees(r)
+}
+
+func openRepository(config *openConfig) (*Repository, error) {
+	r, err := gitRepositoryOpenExt(config.path, config.flags, config.ceilingDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.objectDir != "" || len(config.alternates) > 0 {
+		if err := setRepositoryODB(r, config.objectDir, config.alternates); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.workTree != "" {
+		if err := gitRepositorySetWorkdir(r, config.workTree, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.namespace != "" {
+		if err := setNamespace(r, config.namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Repository{r}, nil
+}
+
+// setNamespace sets the namespace of a repository, and reloads its reference
+// database, which reads the namespace when it is opened. Other reference
+// databases cannot be reloaded, so the namespace is refused for them.
+func setNamespace(r *gitRepository, ns string) error {
+	if r.customRefdb {
+		return errNamespaceRefdb
+	}
+	if err := gitRepositorySetNamespace(r, ns); err != nil {
+		return err
+	}
+
+	refdb, err := gitRefdbOpen(r)
+	if err != nil {
+		return err
+	}
+	defer refdb.free()
+
+	gitRepositorySetRefdb(r, refdb)
+	return nil
+}
+
+func setRepositoryODB(r *gitRepository, objectDir string, alternates []string) error {
+	var (
+		odb *gitODB
+		err error
+	)
+
+	if objectDir != "" {
+		if odb, err = gitODBOpen(objectDir); err != nil {
+			return err
+		}
+	} else if odb, err = gitRepositoryODB(r); err != nil {
+		return err
+	}
+
+	for _, dir := range alternates {
+		if err := gitODBAddDiskAlternate(odb, dir); err != nil {
+			return err
+		}
+	}
+
+	gitRepositorySetODB(r, odb)
+	return nil
+}
+
 func (r Repository) isDetachedHead() bool {
 	return gitRepositoryHeadDetached(r.gitRepository)
 }
@@ -152,6 +925,38 @@ func (r Repository) tip() (*Commit, error) {
 
 type gitRepository struct {
 	ptr *C.git_repository
+
+	mu     sync.Mutex
+	calls  int
+	closed bool
+
+	// customRefdb is set once the on-disk reference database has been
+	// replaced by one built by this package.
+	customRefdb bool
+}
+
+// acquire holds the repository open for the duration of a call into
+// libgit2, so that closing the repository waits for in-flight calls. It
+// returns ErrClosed once the repository is closed.
+func (r *gitRepository) acquire() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+	r.calls++
+	return nil
+}
+
+func (r *gitRepository) release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.calls--; r.closed && r.calls == 0 {
+		C.git_repository_free(r.ptr)
+		r.ptr = nil
+	}
 }
 
 func (r *gitRepository) init() {
@@ -160,7 +965,17 @@ func (r *gitRepository) init() {
 
 func (r *gitRepository) free() {
 	runtime.SetFinalizer(r, nil)
-	C.git_repository_free(r.ptr)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	if r.closed = true; r.calls == 0 {
+		C.git_repository_free(r.ptr)
+		r.ptr = nil
+	}
 }
 
 func gitInitRepository(path string, isBare bool) (*gitRepository, error) {
@@ -178,6 +993,42 @@ func gitInitRepository(path string, isBare bool) (*gitRepository, error) {
 	return r, nil
 }
 
+func gitRepositoryDetachHead(repo *gitRepository, signature *gitSignature,
+	logMessage string) error {
+
+	cmsg := C.CString(logMessage)
+	defer C.free(unsafe.Pointer(cmsg))
+
+	return unwrapErr(C.libgit2_repository_detach_head(repo.ptr, signature.ptr, cmsg))
+}
+
+func gitRepositoryDiscover(startPath string, acrossFS bool,
+	ceilingDirs []string) (string, error) {
+
+	buf := &C.git_buf{}
+	defer C.git_buf_free(buf)
+
+	cpath := C.CString(startPath)
+	defer C.free(unsafe.Pointer(cpath))
+
+	var cdirs *C.char
+	if len(ceilingDirs) > 0 {
+		cdirs = C.CString(strings.Join(ceilingDirs, string(filepath.ListSeparator)))
+		defer C.free(unsafe.Pointer(cdirs))
+	}
+
+	err := unwrapErr(C.libgit2_repository_discover(buf, cpath, cbool(acrossFS),
+		cdirs))
+	if err != nil {
+		return "", err
+	}
+	return C.GoString(buf.ptr), nil
+}
+
+func gitRepositoryGetNamespace(repo *gitRepository) string {
+	return C.GoString(C.git_repository_get_namespace(repo.ptr))
+}
+
 func gitRepositoryHead(repo *gitRepository) (*gitReference, error) {
 	r := new(gitReference)
 
@@ -200,19 +1051,190 @@ func gitRepositoryIsBare(repo *gitRepository) bool {
 	return C.git_repository_is_bare(repo.ptr) != 0
 }
 
+func gitRepositoryIsShallow(repo *gitRepository) bool {
+	return C.git_repository_is_shallow(repo.ptr) == 1
+}
+
+func gitRepositoryInitExt(config *initConfig) (*gitRepository, error) {
+	r := new(gitRepository)
+
+	opts := &C.git_repository_init_options{}
+	err := unwrapErr(C.libgit2_repository_init_init_options(opts,
+		C.GIT_REPOSITORY_INIT_OPTIONS_VERSION))
+	if err != nil {
+		return nil, err
+	}
+
+	opts.flags = C.uint32_t(config.flags)
+	opts.mode = C.uint32_t(config.mode)
+
+	for _, opt := range []struct {
+		val string
+		ptr **C.char
+	}{
+		{config.workdir, &opts.workdir_path},
+		{config.description, &opts.description},
+		{config.template, &opts.template_path},
+		{config.initialHead, &opts.initial_head},
+		{config.originURL, &opts.origin_url},
+	} {
+		if opt.val != "" {
+			*opt.ptr = C.CString(opt.val)
+			defer C.free(unsafe.Pointer(*opt.ptr))
+		}
+	}
+
+	cpath := C.CString(config.path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	if err := unwrapErr(C.libgit2_repository_init_ext(&r.ptr, cpath, opts)); err != nil {
+		return nil, err
+	}
+	r.init()
+	return r, nil
+}
+
+func gitRepositoryMessage(repo *gitRepository) (string, error) {
+	buf := &C.git_buf{}
+	defer C.git_buf_free(buf)
+
+	if err := unwrapErr(C.libgit2_repository_message(buf, repo.ptr)); err != nil {
+		return "", err
+	}
+	return C.GoString(buf.ptr), nil
+}
+
+func gitRepositoryMessageRemove(repo *gitRepository) error {
+	return unwrapErr(C.libgit2_repository_message_remove(repo.ptr))
+}
+
+func gitRepositoryNew() (*gitRepository, error) {
+	r := new(gitRepository)
+
+	if err := unwrapErr(C.libgit2_repository_new(&r.ptr)); err != nil {
+		return nil, err
+	}
+	r.init()
+	return r, nil
+}
+
+func gitRepositoryODB(repo *gitRepository) (*gitODB, error) {
+	o := new(gitODB)
+
+	if err := unwrapErr(C.libgit2_repository_odb(&o.ptr, repo.ptr)); err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
+
 func gitRepositoryOpen(path string) (*gitRepository, error) {
 	r := new(gitRepository)
 
 	cpath := C.CString(path)
 	defer C.free(unsafe.Pointer(cpath))
 
-	return r, unwrapErr(C.libgit2_repository_open(&r.ptr, cpath))
+	if err := unwrapErr(C.libgit2_repository_open(&r.ptr, cpath)); err != nil {
+		return nil, err
+	}
+	r.init()
+	return r, nil
+}
+
+func gitRepositoryOpenExt(path string, flags repositoryOpenFlag,
+	ceilingDirs []string) (*gitRepository, error) {
+
+	r := new(gitRepository)
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	var cdirs *C.char
+	if len(ceilingDirs) > 0 {
+		cdirs = C.CString(strings.Join(ceilingDirs, string(filepath.ListSeparator)))
+		defer C.free(unsafe.Pointer(cdirs))
+	}
+
+	err := unwrapErr(C.libgit2_repository_open_ext(&r.ptr, cpath, C.uint(flags),
+		cdirs))
+	if err != nil {
+		return nil, err
+	}
+	r.init()
+	return r, nil
 }
 
 func gitRepositoryPath(repo *gitRepository) string {
 	return C.GoString(C.git_repository_path(repo.ptr))
 }
 
+func gitRepositorySetConfig(repo *gitRepository, cfg *gitConfig) {
+	C.git_repository_set_config(repo.ptr, cfg.ptr)
+}
+
+func gitRepositorySetHead(repo *gitRepository, refname string,
+	signature *gitSignature, logMessage string) error {
+
+	cname := C.CString(refname)
+	defer C.free(unsafe.Pointer(cname))
+
+	cmsg := C.CString(logMessage)
+	defer C.free(unsafe.Pointer(cmsg))
+
+	return unwrapErr(C.libgit2_repository_set_head(repo.ptr, cname, signature.ptr,
+		cmsg))
+}
+
+func gitRepositorySetHeadDetached(repo *gitRepository, oid OID,
+	signature *gitSignature, logMessage string) error {
+
+	cmsg := C.CString(logMessage)
+	defer C.free(unsafe.Pointer(cmsg))
+
+	return unwrapErr(C.libgit2_repository_set_head_detached(repo.ptr, oid.ptr(),
+		signature.ptr, cmsg))
+}
+
+func gitRepositorySetIndex(repo *gitRepository, idx *gitIndex) {
+	C.git_repository_set_index(repo.ptr, idx.ptr)
+}
+
+func gitRepositorySetNamespace(repo *gitRepository, ns string) error {
+	var cns *C.char
+	if ns != "" {
+		cns = C.CString(ns)
+		defer C.free(unsafe.Pointer(cns))
+	}
+
+	return unwrapErr(C.libgit2_repository_set_namespace(repo.ptr, cns))
+}
+
+func gitRepositorySetODB(repo *gitRepository, odb *gitODB) {
+	C.git_repository_set_odb(repo.ptr, odb.ptr)
+}
+
+func gitRepositorySetRefdb(repo *gitRepository, refdb *gitRefdb) {
+	C.git_repository_set_refdb(repo.ptr, refdb.ptr)
+}
+
+func gitRepositorySetWorkdir(repo *gitRepository, workdir string,
+	updateGitlink bool) error {
+
+	cdir := C.CString(workdir)
+	defer C.free(unsafe.Pointer(cdir))
+
+	return unwrapErr(C.libgit2_repository_set_workdir(repo.ptr, cdir,
+		cbool(updateGitlink)))
+}
+
+func gitRepositoryState(repo *gitRepository) RepositoryState {
+	return RepositoryState(C.git_repository_state(repo.ptr))
+}
+
+func gitRepositoryStateCleanup(repo *gitRepository) error {
+	return unwrapErr(C.libgit2_repository_state_cleanup(repo.ptr))
+}
+
 func gitRepositoryWorkdir(repo *gitRepository) string {
 	return C.GoString(C.git_repository_workdir(repo.ptr))
 }
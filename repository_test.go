@@ -3,7 +3,9 @@ package libgit2
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -91,6 +93,100 @@ func TestRepositoryInitBare(t *testing.T) {
 	}
 }
 
+func TestRepositoryInitWith(t *testing.T) {
+	dir := filepath.Join(rndstr(), rndstr())
+
+	if _, err := InitRepositoryWith(dir); err == nil {
+		t.Fatal("want error for missing parent directory")
+	}
+
+	url := "https://example.com/repo.git"
+	repo, err := InitRepositoryWith(dir,
+		MkPath,
+		InitialHead("main"),
+		Description("a test repository"),
+		Origin(url),
+		Shared(SharedGroup),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := ioutil.ReadFile(filepath.Join(repo.Path(), "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ref: refs/heads/main\n"; string(head) != want {
+		t.Errorf("want HEAD %q, got %q", want, head)
+	}
+
+	desc, err := ioutil.ReadFile(filepath.Join(repo.Path(), "description"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a test repository"; !strings.HasPrefix(string(desc), want) {
+		t.Errorf("want description %q, got %q", want, desc)
+	}
+
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := cfg.GetString("remote.origin.url"); err != nil {
+		t.Fatal(err)
+	} else if got != url {
+		t.Errorf("want origin url %q, got %q", url, got)
+	}
+	if got, err := cfg.GetInt64("core.sharedRepository"); err != nil {
+		t.Fatal(err)
+	} else if got != 1 {
+		t.Errorf("want core.sharedRepository %d, got %d", 1, got)
+	}
+}
+
+func TestRepositoryInitWithTemplate(t *testing.T) {
+	tmpl := rndstr()
+	if err := os.MkdirAll(filepath.Join(tmpl, "info"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	exclude := []byte("*.tmp\n")
+	if err := ioutil.WriteFile(filepath.Join(tmpl, "info", "exclude"), exclude, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := InitRepositoryWith(rndstr(), InitBare, Template(tmpl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.IsBare() {
+		t.Error("got normal repo, want bare")
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(repo.Path(), "info", "exclude"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(exclude, got) {
+		t.Errorf("want info/exclude %q, got %q", exclude, got)
+	}
+}
+
+func TestRepositoryInitWithSeparateWorkdir(t *testing.T) {
+	workdir, err := filepath.Abs(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := InitRepositoryWith(rndstr()+".git", SeparateWorkdir(workdir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := workdir + "/"; repo.Workdir() != want {
+		t.Errorf("want repo workdir %q, got %q", want, repo.Workdir())
+	}
+}
+
 func TestRepositoryDetachedHead(t *testing.T) {
 	repo := mustInitTestRepo(t)
 	pushd(t, repo.Workdir())
@@ -129,6 +225,62 @@ func TestRepositoryHead(t *testing.T) {
 	}
 }
 
+func TestRepositoryState(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	if state := repo.State(); state != StateNone {
+		t.Fatalf("want repo state %q, got %q", StateNone, state)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mergeHead := []byte(head.target().String() + "\n")
+	if err := ioutil.WriteFile(filepath.Join(repo.Path(), "MERGE_HEAD"), mergeHead, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if state := repo.State(); state != StateMerge {
+		t.Errorf("want repo state %q, got %q", StateMerge, state)
+	}
+
+	if err := repo.CleanupState(); err != nil {
+		t.Fatal(err)
+	}
+	if state := repo.State(); state != StateNone {
+		t.Errorf("want repo state %q, got %q", StateNone, state)
+	}
+}
+
+func TestRepositoryMergeMessage(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	want := "Merge branch 'topic'\n"
+	if err := ioutil.WriteFile(filepath.Join(repo.Path(), "MERGE_MSG"), []byte(want), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := repo.MergeMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != got {
+		t.Errorf("want merge message %q, got %q", want, got)
+	}
+
+	if err := repo.RemoveMergeMessage(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.MergeMessage(); !isNotFound(err) {
+		t.Errorf("want errNotFound error, got %v", err)
+	}
+}
+
 func TestRepositoryOpen(t *testing.T) {
 	repo := mustInitTestRepo(t)
 
@@ -137,6 +289,174 @@ func TestRepositoryOpen(t *testing.T) {
 	}
 }
 
+func TestRepositoryOpenSearch(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir := filepath.Join(repo.Workdir(), rndstr())
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenRepository(dir, Search()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, options := range [][]OpenOption{nil, {Search(), NoSearch()}} {
+		_, err := OpenRepository(dir, options...)
+		gitErr, ok := err.(*gitError)
+		if err == nil || !ok {
+			t.Fatal("want errNotFound error")
+		}
+		if gitErr.code != errNotFound {
+			t.Errorf("want error code %d, got %d", errNotFound, gitErr.code)
+		}
+	}
+}
+
+func TestRepositoryOpenBare(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	got, err := OpenRepository(repo.Workdir(), OpenBare())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsBare() {
+		t.Error("got normal repo, want bare")
+	}
+}
+
+func TestRepositoryOpenWorkTree(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir, err := filepath.Abs(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := OpenRepository(repo.Path(), WorkTree(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := dir + "/"; got.Workdir() != want {
+		t.Errorf("want repo workdir %q, got %q", want, got.Workdir())
+	}
+}
+
+func TestRepositoryOpenFromEnv(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir, err := filepath.Abs(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("GIT_DIR", repo.Path())
+	os.Setenv("GIT_WORK_TREE", dir)
+	defer os.Unsetenv("GIT_DIR")
+	defer os.Unsetenv("GIT_WORK_TREE")
+
+	got, err := OpenRepository(rndstr(), FromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repo.Path(); got.Path() != want {
+		t.Errorf("want repo path %q, got %q", want, got.Path())
+	}
+	if want := dir + "/"; got.Workdir() != want {
+		t.Errorf("want repo workdir %q, got %q", want, got.Workdir())
+	}
+}
+
+func TestRepositoryOpenFromEnvSearch(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir := filepath.Join(repo.Workdir(), rndstr())
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := OpenRepository(dir, FromEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repo.Path(); got.Path() != want {
+		t.Errorf("want repo path %q, got %q", want, got.Path())
+	}
+
+	if _, err := OpenRepository(dir, FromEnv(), NoSearch()); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func TestRepositoryDiscover(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir := filepath.Join(repo.Workdir(), rndstr(), rndstr())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, path, err := DiscoverRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repo.Path(); path != want {
+		t.Errorf("want discovered path %q, got %q", want, path)
+	}
+	if want := repo.Workdir(); got.Workdir() != want {
+		t.Errorf("want discovered workdir %q, got %q", want, got.Workdir())
+	}
+}
+
+func TestRepositoryDiscoverCeilingDirs(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir := filepath.Join(repo.Workdir(), rndstr())
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := DiscoverRepository(dir, CeilingDirs(repo.Workdir()))
+	gitErr, ok := err.(*gitError)
+	if err == nil || !ok {
+		t.Fatal("want errNotFound error")
+	}
+	if gitErr.code != errNotFound {
+		t.Errorf("want error code %d, got %d", errNotFound, gitErr.code)
+	}
+}
+
+func TestRepositoryDiscoverGitdirFile(t *testing.T) {
+	repo := mustInitTestRepo(t)
+
+	dir, err := filepath.Abs(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	gitdir := []byte("gitdir: " + repo.Path() + "\n")
+	if err := ioutil.WriteFile(filepath.Join(dir, ".git"), gitdir, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, path, err := DiscoverRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := repo.Path(); path != want {
+		t.Errorf("want discovered path %q, got %q", want, path)
+	}
+}
+
 func TestRepositoryLocalBranch(t *testing.T) {
 	repo := mustInitTestRepo(t)
 	pushd(t, repo.Workdir())
@@ -181,3 +501,253 @@ func mustSeedTestFile(t *testing.T, repo *Repository) string {
 	}
 	return f
 }
+
+func TestRepositoryClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 2)
+
+	commit, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := commit.Message()
+
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Head(); err != ErrClosed {
+		t.Errorf("want error %v, got %v", ErrClosed, err)
+	}
+	if _, err := repo.Walk(); err != ErrClosed {
+		t.Errorf("want error %v, got %v", ErrClosed, err)
+	}
+
+	if want, got := msg, commit.Message(); want != got {
+		t.Errorf("want commit message %q, got %q", want, got)
+	}
+	if _, err := commit.Parents(); err != ErrClosed {
+		t.Errorf("want error %v, got %v", ErrClosed, err)
+	}
+
+	if err := commit.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := commit.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkerClose(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 10)
+
+	walker, err := repo.Walk()
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-walker.C
+
+	if err := walker.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := walker.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestShallowRepository(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 5)
+
+	if repo.IsShallow() {
+		t.Fatal("want full repository, got shallow")
+	}
+
+	walker, err := repo.Walk(Sorting(SortTopological))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := walker.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := commits[2]
+	shallow := filepath.Join(repo.Path(), "shallow")
+	if err := ioutil.WriteFile(shallow, []byte(root.String()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !repo.IsShallow() {
+		t.Fatal("want shallow repository, got full")
+	}
+
+	roots, err := repo.ShallowRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 1 || roots[0].String() != root.String() {
+		t.Errorf("want shallow roots [%s], got %v", root, roots)
+	}
+
+	if ok, err := root.IsShallowRoot(); err != nil || !ok {
+		t.Errorf("want shallow root %s, got %t (%v)", root, ok, err)
+	}
+	if parents, err := root.Parents(); err != nil || len(parents) != 0 {
+		t.Errorf("want no parents, got %v (%v)", parents, err)
+	}
+
+	for _, mode := range []SortMode{SortNone, SortTime, SortTopological, SortTopological | SortReverse} {
+		walker, err := repo.Walk(Sorting(mode))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := walker.Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want := commits[:3]; len(want) != len(got) {
+			t.Errorf("sort mode %d: want %d commits, got %d", mode, len(want), len(got))
+			continue
+		}
+		if mode == SortTopological {
+			for i, c := range commits[:3] {
+				if c.String() != got[i].String() {
+					t.Errorf("want commit %d to be %s, got %s", i, c, got[i])
+				}
+			}
+		}
+		if mode&SortReverse != 0 && got[0].String() != root.String() {
+			t.Errorf("want walk to start at shallow root %s, got %s", root, got[0])
+		}
+	}
+
+	rs, err := repo.RevParseRange("HEAD~1..HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+
+	walker, err = repo.Walk(Range(rs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := walker.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].String() != commits[0].String() {
+		t.Errorf("want range walk [%s], got %v", commits[0], got)
+	}
+}
+
+func TestRepositoryNamespace(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+	if _, err := repo.CreateBranch("shared"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetNamespace("tenant"); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "tenant", repo.Namespace(); want != got {
+		t.Errorf("want namespace %q, got %q", want, got)
+	}
+
+	if _, err := repo.Head(); err == nil {
+		t.Error("want error resolving HEAD of a new namespace")
+	}
+	if err := repo.SetHead("refs/heads/master"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.LocalBranch("shared"); !isNotFound(err) {
+		t.Errorf("want branch outside of namespace to be hidden, got %v", err)
+	}
+
+	mustSeedRepo(t, repo)
+	name := rndstr()
+	if _, err := repo.CreateBranch(name); err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "refs/heads/master", gitReferenceName(head.gitReference); want != got {
+		t.Errorf("want HEAD %q, got %q", want, got)
+	}
+
+	walker, err := repo.Branches()
+	if err != nil {
+		t.Fatal(err)
+	}
+	branches, err := walker.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 2, len(branches); want != got {
+		t.Errorf("want %d branches in namespace, got %d", want, got)
+	}
+
+	if err := repo.SetNamespace(""); err != nil {
+		t.Fatal(err)
+	}
+	if ns := repo.Namespace(); ns != "" {
+		t.Errorf("want no namespace, got %q", ns)
+	}
+	if _, err := repo.LocalBranch("shared"); err != nil {
+		t.Error(err)
+	}
+	if _, err := repo.LocalBranch(name); !isNotFound(err) {
+		t.Errorf("want branch in namespace to be hidden, got %v", err)
+	}
+	if _, _, err := repo.RevParse("refs/namespaces/tenant/refs/heads/" + name); err != nil {
+		t.Errorf("want branch %q in namespace: %v", name, err)
+	}
+}
+
+func TestRepositoryNamespaceRefBackend(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	if err := repo.SetRefBackend(newMemRefBackend()); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SetNamespace("tenant"); err != errNamespaceRefdb {
+		t.Errorf("want error %v, got %v", errNamespaceRefdb, err)
+	}
+
+	mem, _, err := NewMempackRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mem.Close()
+
+	if err := mem.SetNamespace("tenant"); err != errNamespaceRefdb {
+		t.Errorf("want error %v, got %v", errNamespaceRefdb, err)
+	}
+	if ns := mem.Namespace(); ns != "" {
+		t.Errorf("want no namespace, got %q", ns)
+	}
+}
@@ -0,0 +1,205 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+import "unsafe"
+
+// RevspecFlag describes the kind of a parsed revision expression.
+type RevspecFlag uint
+
+const (
+	// RevspecSingle is a single revision, such as HEAD~3 or main^{tree}.
+	RevspecSingle RevspecFlag = C.GIT_REVPARSE_SINGLE
+	// RevspecRange is a range of revisions, such as a..b.
+	RevspecRange RevspecFlag = C.GIT_REVPARSE_RANGE
+	// RevspecMergeBase is set along with RevspecRange for a symmetric
+	// difference, such as a...b.
+	RevspecMergeBase RevspecFlag = C.GIT_REVPARSE_MERGE_BASE
+)
+
+// Revspec is a parsed revision expression. It can be passed to
+// Repository.Walk with the Range option.
+type Revspec struct {
+	// From is the single revision, or the left side of a range.
+	From Object
+	// To is the right side of a range, and nil for a single revision.
+	To Object
+
+	Flags RevspecFlag
+}
+
+func revParse(repo Repository, spec string) (Object, *Reference, error) {
+	obj, ref, err := gitRevparseExt(repo.gitRepository, spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	o, err := obj.object()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reference *Reference
+	if ref != nil {
+		reference = &Reference{ref}
+	}
+	return o, reference, nil
+}
+
+func revParseRange(repo Repository, spec string) (*Revspec, error) {
+	from, to, flags, err := gitRevparse(repo.gitRepository, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &Revspec{Flags: flags}
+	if rs.From, err = from.object(); err != nil {
+		if to != nil {
+			to.free()
+		}
+		return nil, err
+	}
+	if to != nil {
+		if rs.To, err = to.object(); err != nil {
+			rs.Close()
+			return nil, err
+		}
+	}
+	return rs, nil
+}
+
+// Close releases the objects of the revspec.
+func (s *Revspec) Close() error {
+	for _, obj := range []Object{s.From, s.To} {
+		if obj != nil {
+			obj.Close()
+		}
+	}
+	return nil
+}
+
+// IsRange reports whether the revspec is a range rather than a single
+// revision.
+func (s *Revspec) IsRange() bool {
+	return s.Flags&RevspecRange != 0
+}
+
+// walkCommits returns the commits to push onto, and hide from, a walk of
+// the revspec: a single revision walks its history, a..b walks the commits
+// reachable from b but not a, and a...b the commits reachable from either
+// side but not from any of their merge bases. Criss-cross histories have
+// more than one.
+func (s *Revspec) walkCommits(repo *gitRepository) (push, hide []OID, err error) {
+	from, err := peelCommitID(repo, s.From.ID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !s.IsRange() {
+		return []OID{from}, nil, nil
+	}
+
+	to, err := peelCommitID(repo, s.To.ID())
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.Flags&RevspecMergeBase == 0 {
+		return []OID{to}, []OID{from}, nil
+	}
+
+	bases, err := gitMergeBases(repo, from, to)
+	if isNotFound(err) {
+		// unrelated histories, nothing in common to hide
+		return []OID{from, to}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return []OID{from, to}, bases, nil
+}
+
+func peelCommitID(repo *gitRepository, oid OID) (OID, error) {
+	obj, err := gitObjectLookup(repo, oid, ObjectAny)
+	if err != nil {
+		return OID{}, err
+	}
+	defer obj.free()
+
+	commit, err := gitObjectPeel(obj, ObjectCommit)
+	if err != nil {
+		return OID{}, err
+	}
+	defer commit.free()
+
+	return gitObjectID(commit), nil
+}
+
+func gitMergeBases(repo *gitRepository, one, two OID) ([]OID, error) {
+	var arr C.git_oidarray
+	if err := unwrapErr(C.libgit2_merge_bases(&arr, repo.ptr, one.ptr(), two.ptr())); err != nil {
+		return nil, err
+	}
+	defer C.git_oidarray_free(&arr)
+
+	oids := make([]OID, int(arr.count))
+	for i := range oids {
+		oids[i] = newOID((*C.git_oid)(unsafe.Pointer(uintptr(unsafe.Pointer(arr.ids)) +
+			uintptr(i)*unsafe.Sizeof(*arr.ids))))
+	}
+	return oids, nil
+}
+
+func gitRevparse(repo *gitRepository, spec string) (*gitObject, *gitObject, RevspecFlag, error) {
+	var rs C.git_revspec
+
+	cspec := C.CString(spec)
+	defer C.free(unsafe.Pointer(cspec))
+
+	if err := unwrapErr(C.libgit2_revparse(&rs, repo.ptr, cspec)); err != nil {
+		return nil, nil, 0, err
+	}
+
+	from := &gitObject{ptr: rs.from, repo: repo}
+	from.init()
+
+	var to *gitObject
+	if rs.to != nil {
+		to = &gitObject{ptr: rs.to, repo: repo}
+		to.init()
+	}
+	return from, to, RevspecFlag(rs.flags), nil
+}
+
+func gitRevparseExt(repo *gitRepository, spec string) (*gitObject, *gitReference, error) {
+	o := &gitObject{repo: repo}
+
+	var ref *C.git_reference
+
+	cspec := C.CString(spec)
+	defer C.free(unsafe.Pointer(cspec))
+
+	if err := unwrapErr(C.libgit2_revparse_ext(&o.ptr, &ref, repo.ptr, cspec)); err != nil {
+		return nil, nil, err
+	}
+	o.init()
+
+	if ref == nil {
+		return o, nil, nil
+	}
+
+	r := &gitReference{ref}
+	r.init()
+	return o, r, nil
+}
+
+func gitRevparseSingle(repo *gitRepository, spec string) (*gitObject, error) {
+	o := &gitObject{repo: repo}
+
+	cspec := C.CString(spec)
+	defer C.free(unsafe.Pointer(cspec))
+
+	if err := unwrapErr(C.libgit2_revparse_single(&o.ptr, repo.ptr, cspec)); err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
@@ -0,0 +1,223 @@
+package libgit2
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRevParse(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 3)
+
+	walk, err := repo.Walk(Sorting(SortTopological))
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.CreateLightweightTag("v1.0", TagTarget(history[1])); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec, ref string
+		want      OID
+	}{
+		{spec: "HEAD", ref: "refs/heads/master", want: history[0].ID()},
+		{spec: "HEAD~2", want: history[2].ID()},
+		{spec: "master^", ref: "refs/heads/master", want: history[1].ID()},
+		{spec: "v1.0", ref: "refs/tags/v1.0", want: history[1].ID()},
+		{spec: history[2].String()[:7], want: history[2].ID()},
+	}
+
+	for _, test := range tests {
+		obj, ref, err := repo.RevParse(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+		if want, got := test.want, obj.ID(); want != got {
+			t.Errorf("%s: want object %s, got %s", test.spec, want, got)
+		}
+
+		switch {
+		case test.ref == "" && ref != nil:
+			t.Errorf("%s: want no reference, got %s", test.spec, ref.Name())
+		case test.ref != "" && ref == nil:
+			t.Errorf("%s: want reference %s, got none", test.spec, test.ref)
+		case ref != nil && ref.Name() != test.ref:
+			t.Errorf("%s: want reference %s, got %s", test.spec, test.ref, ref.Name())
+		}
+		obj.Close()
+	}
+
+	obj, _, err := repo.RevParse("master@{1}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := history[1].ID(), obj.ID(); want != got {
+		t.Errorf("want reflog entry %s, got %s", want, got)
+	}
+
+	obj, _, err = repo.RevParse("HEAD^{tree}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := ObjectTree, obj.Type(); want != got {
+		t.Errorf("want type %s, got %s", want, got)
+	}
+
+	if _, _, err := repo.RevParse("HEAD~10"); err == nil {
+		t.Error("want error parsing revision past the root commit")
+	}
+	if _, _, err := repo.RevParse("HEAD~2..HEAD"); err == nil {
+		t.Error("want error parsing a range as a single revision")
+	}
+}
+
+func TestRevParsePath(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	tree := mustWriteTestTree(t, repo, map[string]string{"dir/file": "contents\n"})
+	defer tree.Close()
+
+	if err := ioutil.WriteFile(filepath.Join(repo.Workdir(), "dir", "file"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, _, err := repo.RevParse("HEAD:dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, ok := obj.(*Blob)
+	if !ok {
+		t.Fatalf("want *Blob, got %T", obj)
+	}
+	if want, got := "contents\n", string(mustReadBlob(t, blob)); want != got {
+		t.Errorf("want blob contents %q, got %q", want, got)
+	}
+}
+
+func TestRevParseRange(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 2)
+	if _, err := repo.CreateBranch("side"); err != nil {
+		t.Fatal(err)
+	}
+	mustSeedRepoN(t, repo, 2)
+
+	if err := repo.SetHead("refs/heads/side"); err != nil {
+		t.Fatal(err)
+	}
+	mustSeedRepo(t, repo)
+
+	tests := []struct {
+		spec  string
+		flags RevspecFlag
+		n     int
+	}{
+		{spec: "side", flags: RevspecSingle, n: 3},
+		{spec: "master..side", flags: RevspecRange, n: 1},
+		{spec: "side..master", flags: RevspecRange, n: 2},
+		{spec: "master...side", flags: RevspecRange | RevspecMergeBase, n: 3},
+		{spec: "master~2..master", flags: RevspecRange, n: 2},
+	}
+
+	for _, test := range tests {
+		rs, err := repo.RevParseRange(test.spec)
+		if err != nil {
+			t.Errorf("%s: %v", test.spec, err)
+			continue
+		}
+		if want, got := test.flags, rs.Flags; want != got {
+			t.Errorf("%s: want flags %b, got %b", test.spec, want, got)
+		}
+		if want, got := test.flags&RevspecRange != 0, rs.To != nil; want != got {
+			t.Errorf("%s: want range end %t, got %t", test.spec, want, got)
+		}
+
+		walk, err := repo.Walk(Range(rs))
+		if err != nil {
+			t.Fatal(err)
+		}
+		commits, err := walk.Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := test.n, len(commits); want != got {
+			t.Errorf("%s: want %d commits, got %d", test.spec, want, got)
+		}
+
+		walk.Close()
+		rs.Close()
+	}
+
+	if _, err := repo.RevParseRange("master..nope"); err == nil {
+		t.Error("want error parsing range with an unknown revision")
+	}
+}
+
+func TestRevParseRangeCrissCross(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+	for _, name := range []string{"x", "y"} {
+		if _, err := repo.CreateBranch(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commit := func(branch string, parents ...*Commit) *Commit {
+		if err := repo.SetHead("refs/heads/" + branch); err != nil {
+			t.Fatal(err)
+		}
+
+		options := []CommitOption{AllowEmpty, Message(rndstr())}
+		if len(parents) > 0 {
+			options = append(options, Parents(parents...))
+		}
+		c, err := repo.Commit(options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	// x1 and y1 are both merge bases of x and y
+	x1 := commit("x")
+	y1 := commit("y")
+	commit("x", x1, y1)
+	commit("y", y1, x1)
+
+	rs, err := repo.RevParseRange("x...y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+
+	walk, err := repo.Walk(Range(rs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := walk.Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 2, len(commits); want != got {
+		t.Errorf("want %d commits, got %d", want, got)
+	}
+}
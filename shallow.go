@@ -0,0 +1,305 @@
+package libgit2
+
+import (
+	"container/heap"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shallowRoots reads the commits listed in the shallow file of a repository.
+// The file is only read for a shallow repository, so that complete
+// repositories do not pay for it on every Commit.Parents call.
+func shallowRoots(repo *gitRepository) ([]OID, error) {
+	if !gitRepositoryIsShallow(repo) {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(gitRepositoryPath(repo), "shallow"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []OID
+	for _, line := range strings.Fields(string(data)) {
+		oid, err := ParseOID(line)
+		if err != nil {
+			return nil, err
+		}
+		roots = append(roots, oid)
+	}
+	return roots, nil
+}
+
+func isShallowRoot(roots []OID, oid OID) bool {
+	for _, root := range roots {
+		if root == oid {
+			return true
+		}
+	}
+	return false
+}
+
+// shallowWalk walks the history of a shallow repository. libgit2 parses the
+// parents of every commit it walks, hidden ones included, so its revwalk
+// fails at the shallow roots whose parents are missing, and hiding those
+// parents fails the same way. The walk is done here instead, treating the
+// shallow roots as root commits.
+//
+// Commits are streamed newest first from a queue ordered by commit time, as
+// the revwalk does. Only SortTopological and SortReverse, which need every
+// commit before the first one can be returned, collect the whole walk up
+// front, as libgit2 does for them as well.
+type shallowWalk struct {
+	repo  Repository
+	roots map[OID]bool
+
+	queue commitQueue
+	seen  map[OID]bool
+
+	// sorted holds the whole walk for the modes that need it.
+	sorted []*Commit
+}
+
+// newShallowWalk walks the commits reachable from starts but not from hidden.
+// The commits reachable from hidden are marked when the walk is created, as
+// libgit2 does when commits are hidden.
+func newShallowWalk(repo Repository, starts, hidden []*Commit, mode SortMode) (*shallowWalk, error) {
+	roots, err := shallowRoots(repo.gitRepository)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &shallowWalk{
+		repo:  repo,
+		roots: map[OID]bool{},
+		seen:  map[OID]bool{},
+	}
+	for _, oid := range roots {
+		w.roots[oid] = true
+	}
+
+	if err := w.hide(hidden); err != nil {
+		return nil, err
+	}
+	for _, c := range starts {
+		w.push(c)
+	}
+
+	if mode&(SortTopological|SortReverse) == 0 {
+		return w, nil
+	}
+
+	var commits []*Commit
+	for {
+		c, err := w.pop()
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			break
+		}
+		commits = append(commits, c)
+	}
+
+	if mode&SortTopological != 0 {
+		if commits, err = w.sortTopological(commits); err != nil {
+			return nil, err
+		}
+	}
+	if mode&SortReverse != 0 {
+		for i, j := 0, len(commits)-1; i < j; i, j = i+1, j-1 {
+			commits[i], commits[j] = commits[j], commits[i]
+		}
+	}
+
+	if commits == nil {
+		commits = []*Commit{}
+	}
+	w.sorted = commits
+	return w, nil
+}
+
+func (w *shallowWalk) next() (*Commit, error) {
+	if w.sorted == nil {
+		return w.pop()
+	}
+	if len(w.sorted) == 0 {
+		return nil, nil
+	}
+
+	c := w.sorted[0]
+	w.sorted = w.sorted[1:]
+	return c, nil
+}
+
+// hide marks the commits reachable from hidden as seen, so they are never
+// queued.
+func (w *shallowWalk) hide(hidden []*Commit) error {
+	var queue []OID
+	for _, c := range hidden {
+		queue = append(queue, c.ID())
+	}
+
+	for ; len(queue) > 0; queue = queue[1:] {
+		oid := queue[0]
+		if w.seen[oid] {
+			continue
+		}
+		w.seen[oid] = true
+
+		c, err := lookupCommit(w.repo, oid)
+		if err != nil {
+			return err
+		}
+		parents, err := w.parentIDs(c)
+		c.Close()
+		if err != nil {
+			return err
+		}
+		queue = append(queue, parents...)
+	}
+	return nil
+}
+
+// pop returns the newest queued commit and queues its parents, or nil once
+// the walk is done.
+func (w *shallowWalk) pop() (*Commit, error) {
+	if w.queue.Len() == 0 {
+		return nil, nil
+	}
+	c := heap.Pop(&w.queue).(*Commit)
+
+	parents, err := w.parentIDs(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, oid := range parents {
+		if w.seen[oid] {
+			continue
+		}
+		p, err := lookupCommit(w.repo, oid)
+		if err != nil {
+			return nil, err
+		}
+		w.push(p)
+	}
+	return c, nil
+}
+
+func (w *shallowWalk) push(c *Commit) {
+	if w.seen[c.ID()] {
+		return
+	}
+	w.seen[c.ID()] = true
+	heap.Push(&w.queue, c)
+}
+
+// parentIDs returns the parents of a commit, or none for a shallow root.
+func (w *shallowWalk) parentIDs(c *Commit) ([]OID, error) {
+	if w.roots[c.ID()] {
+		return nil, nil
+	}
+	return c.ParentIDs()
+}
+
+// sortTopological orders commits so that no parent comes before any of its
+// children, keeping the existing order otherwise. Parents missing from
+// commits, which were hidden from the walk, are ignored.
+func (w *shallowWalk) sortTopological(commits []*Commit) ([]*Commit, error) {
+	index := map[OID]int{}
+	for i, c := range commits {
+		index[c.ID()] = i
+	}
+
+	parents := make([][]int, len(commits))
+	children := make([]int, len(commits))
+	for i, c := range commits {
+		ids, err := w.parentIDs(c)
+		if err != nil {
+			return nil, err
+		}
+		for _, oid := range ids {
+			if j, ok := index[oid]; ok {
+				parents[i] = append(parents[i], j)
+				children[j]++
+			}
+		}
+	}
+
+	ready := &indexQueue{}
+	for i := range commits {
+		if children[i] == 0 {
+			heap.Push(ready, i)
+		}
+	}
+
+	sorted := make([]*Commit, 0, len(commits))
+	for ready.Len() > 0 {
+		i := heap.Pop(ready).(int)
+		sorted = append(sorted, commits[i])
+
+		for _, j := range parents[i] {
+			if children[j]--; children[j] == 0 {
+				heap.Push(ready, j)
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// commitQueue is a heap of commits, newest commit time first, and in the
+// order they were queued for equal times.
+type commitQueue struct {
+	commits []*Commit
+	order   []int
+	n       int
+}
+
+func (q *commitQueue) Len() int { return len(q.commits) }
+
+func (q *commitQueue) Less(i, j int) bool {
+	ti, tj := gitCommitTime(q.commits[i].gitCommit), gitCommitTime(q.commits[j].gitCommit)
+	if ti != tj {
+		return ti > tj
+	}
+	return q.order[i] < q.order[j]
+}
+
+func (q *commitQueue) Swap(i, j int) {
+	q.commits[i], q.commits[j] = q.commits[j], q.commits[i]
+	q.order[i], q.order[j] = q.order[j], q.order[i]
+}
+
+func (q *commitQueue) Push(x interface{}) {
+	q.commits = append(q.commits, x.(*Commit))
+	q.order = append(q.order, q.n)
+	q.n++
+}
+
+func (q *commitQueue) Pop() interface{} {
+	n := len(q.commits) - 1
+	c := q.commits[n]
+	q.commits, q.order = q.commits[:n], q.order[:n]
+	return c
+}
+
+// indexQueue is a heap of indexes, smallest first.
+type indexQueue []int
+
+func (q indexQueue) Len() int            { return len(q) }
+func (q indexQueue) Less(i, j int) bool  { return q[i] < q[j] }
+func (q indexQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *indexQueue) Push(x interface{}) { *q = append(*q, x.(int)) }
+
+func (q *indexQueue) Pop() interface{} {
+	old := *q
+	n := len(old) - 1
+	i := old[n]
+	*q = old[:n]
+	return i
+}
@@ -6,6 +6,7 @@ import "C"
 import (
 	"runtime"
 	"time"
+	"unsafe"
 )
 
 // Signature is an action signature (e.g. for committers, taggers, etc)
@@ -13,6 +14,12 @@ type Signature struct {
 	*gitSignature
 }
 
+// Close releases the signature. It may be called more than once.
+func (s *Signature) Close() error {
+	s.free()
+	return nil
+}
+
 func defaultSignature(repo Repository) (*Signature, error) {
 	sig, err := gitSignatureDefault(repo.gitRepository)
 	if err != nil {
@@ -58,6 +65,7 @@ func (s *gitSignature) init() {
 func (s *gitSignature) free() {
 	runtime.SetFinalizer(s, nil)
 	C.git_signature_free(s.ptr)
+	s.ptr = nil
 }
 
 func gitSignatureDefault(repo *gitRepository) (*gitSignature, error) {
@@ -71,6 +79,24 @@ func gitSignatureDefault(repo *gitRepository) (*gitSignature, error) {
 	return s, nil
 }
 
+func gitSignatureNew(name, email string, when time.Time) (*gitSignature, error) {
+	s := new(gitSignature)
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	cemail := C.CString(email)
+	defer C.free(unsafe.Pointer(cemail))
+
+	_, offset := when.Zone()
+	err := unwrapErr(C.libgit2_signature_new(&s.ptr, cname, cemail,
+		C.git_time_t(when.Unix()), C.int(offset/60)))
+	if err != nil {
+		return nil, err
+	}
+	s.init()
+	return s, nil
+}
+
 func gitSignatureDup(sig *gitSignature) (*gitSignature, error) {
 	s := new(gitSignature)
 
@@ -0,0 +1,456 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+const tagsPrefix = "refs/tags/"
+
+// Tag is the representation of an annotated tag object.
+type Tag struct {
+	*gitTag
+}
+
+func createTag(config *tagConfig, message string) (*Tag, error) {
+	target, err := gitObjectLookup(config.repo.gitRepository, config.target.ID(), ObjectAny)
+	if err != nil {
+		return nil, err
+	}
+	defer target.free()
+
+	oid, err := gitTagCreate(config.repo.gitRepository, config.name, target,
+		config.tagger.gitSignature, message, config.force)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := lookupObject(config.repo, oid, ObjectTag)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*Tag), nil
+}
+
+// Close releases the tag. It may be called more than once.
+func (t Tag) Close() error {
+	t.free()
+	return nil
+}
+
+// ID is the object ID of the tag.
+func (t Tag) ID() OID {
+	if t.ptr == nil {
+		return OID{}
+	}
+	return gitTagID(t.gitTag)
+}
+
+// Message is the full message of the tag.
+func (t Tag) Message() string {
+	if t.ptr == nil {
+		return ""
+	}
+	return gitTagMessage(t.gitTag)
+}
+
+// Name is the name of the tag, without the refs/tags/ prefix.
+func (t Tag) Name() string {
+	if t.ptr == nil {
+		return ""
+	}
+	return gitTagName(t.gitTag)
+}
+
+// Peel follows the tag, and any tags it points to, until an object of type
+// typ is found.
+func (t Tag) Peel(typ ObjectType) (Object, error) {
+	return peelObject((*C.git_object)(unsafe.Pointer(t.ptr)), t.repo, typ)
+}
+
+func (t Tag) String() string {
+	return t.ID().String()
+}
+
+// Tagger returns the signature of the creator of the tag. Very old tags may
+// not record a tagger, in which case Tagger returns nil.
+func (t Tag) Tagger() (*Signature, error) {
+	if t.ptr == nil {
+		return nil, errObjectClosed
+	}
+	sig := gitTagTagger(t.gitTag)
+	if sig.ptr == nil {
+		return nil, nil
+	}
+	return dupSignature(sig)
+}
+
+// Target returns the object the tag points to.
+func (t Tag) Target() (Object, error) {
+	if err := t.acquire(); err != nil {
+		return nil, err
+	}
+	defer t.repo.release()
+
+	obj, err := gitTagTarget(t.gitTag)
+	if err != nil {
+		return nil, err
+	}
+	return obj.object()
+}
+
+// TargetID is the object ID of the object the tag points to.
+func (t Tag) TargetID() OID {
+	if t.ptr == nil {
+		return OID{}
+	}
+	return gitTagTargetID(t.gitTag)
+}
+
+// TargetType is the type of the object the tag points to.
+func (t Tag) TargetType() ObjectType {
+	if t.ptr == nil {
+		return ObjectBad
+	}
+	return gitTagTargetType(t.gitTag)
+}
+
+// Type returns ObjectTag.
+func (t Tag) Type() ObjectType {
+	return ObjectTag
+}
+
+// acquire acquires the repository of the tag for the duration of a call,
+// and fails once the tag itself is closed.
+func (t Tag) acquire() error {
+	if t.ptr == nil {
+		return errObjectClosed
+	}
+	return t.repo.acquire()
+}
+
+// TagRef is a reference under refs/tags. The reference of an annotated tag
+// points to a Tag object, while a lightweight tag points directly to the
+// tagged object.
+type TagRef struct {
+	*gitReference
+
+	repo Repository
+}
+
+func createLightweightTag(config *tagConfig) (*TagRef, error) {
+	target, err := gitObjectLookup(config.repo.gitRepository, config.target.ID(), ObjectAny)
+	if err != nil {
+		return nil, err
+	}
+	defer target.free()
+
+	if err := gitTagCreateLightweight(config.repo.gitRepository, config.name, target,
+		config.force); err != nil {
+		return nil, err
+	}
+	return lookupTagRef(config.repo, config.name)
+}
+
+func lookupTagRef(repo Repository, name string) (*TagRef, error) {
+	ref, err := gitReferenceLookup(repo.gitRepository, tagsPrefix+name)
+	if err != nil {
+		return nil, err
+	}
+	return &TagRef{ref, repo}, nil
+}
+
+// Close releases the tag reference. It may be called more than once.
+func (t TagRef) Close() error {
+	t.free()
+	return nil
+}
+
+// Delete deletes the tag reference. An annotated tag object is left in the
+// object database.
+func (t TagRef) Delete() error {
+	if err := t.acquire(); err != nil {
+		return err
+	}
+	defer t.repo.release()
+
+	return gitTagDelete(t.repo.gitRepository, t.Name())
+}
+
+// IsAnnotated reports whether the reference points to an annotated Tag
+// object.
+func (t TagRef) IsAnnotated() (bool, error) {
+	if err := t.acquire(); err != nil {
+		return false, err
+	}
+	defer t.repo.release()
+
+	odb, err := gitRepositoryODB(t.repo.gitRepository)
+	if err != nil {
+		return false, err
+	}
+	defer odb.free()
+
+	_, typ, err := gitODBReadHeader(odb, gitReferenceTarget(t.gitReference))
+	if err != nil {
+		return false, err
+	}
+	return typ == ObjectTag, nil
+}
+
+// Name is the name of the tag, without the refs/tags/ prefix.
+func (t TagRef) Name() string {
+	if t.ptr == nil {
+		return ""
+	}
+	return strings.TrimPrefix(gitReferenceName(t.gitReference), tagsPrefix)
+}
+
+// Peel follows the reference, and any tags it points to, until an object of
+// type typ is found.
+func (t TagRef) Peel(typ ObjectType) (Object, error) {
+	if err := t.acquire(); err != nil {
+		return nil, err
+	}
+	defer t.repo.release()
+
+	obj, err := gitReferencePeel(t.gitReference, t.repo.gitRepository, typ)
+	if err != nil {
+		return nil, err
+	}
+	return obj.object()
+}
+
+// Tag returns the annotated tag object the reference points to, and fails
+// for a lightweight tag.
+func (t TagRef) Tag() (*Tag, error) {
+	if err := t.acquire(); err != nil {
+		return nil, err
+	}
+	defer t.repo.release()
+
+	obj, err := lookupObject(t.repo, t.Target(), ObjectTag)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*Tag), nil
+}
+
+// Target is the object ID the reference points to: the Tag object of an
+// annotated tag, or the tagged object of a lightweight tag.
+func (t TagRef) Target() OID {
+	if t.ptr == nil {
+		return OID{}
+	}
+	return gitReferenceTarget(t.gitReference)
+}
+
+// acquire acquires the repository of the tag reference for the duration of a
+// call, and fails once the reference itself is closed.
+func (t TagRef) acquire() error {
+	if t.ptr == nil {
+		return errReferenceClosed
+	}
+	return t.repo.acquire()
+}
+
+// TagWalker is an in-progress walk of tags in a repo.
+type TagWalker struct {
+	*gitReferenceIterator
+
+	repo Repository
+
+	C <-chan *TagRef
+
+	err error
+
+	co *sync.Once
+	cc chan struct{}
+}
+
+func newTagWalker(r Repository, pattern string) (*TagWalker, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	iter, err := gitReferenceIteratorGlobNew(r.gitRepository, tagsPrefix+pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan *TagRef)
+	w := &TagWalker{
+		gitReferenceIterator: iter,
+		repo:                 r,
+		C:                    c,
+		co:                   &sync.Once{},
+		cc:                   make(chan struct{}),
+	}
+
+	go w.run(c)
+	return w, nil
+}
+
+// Cancel aborts an in-progress walk and drains the tag channel C.
+func (w *TagWalker) Cancel() {
+	w.co.Do(w.cancel)
+}
+
+// Close aborts the walk, if in progress, and releases the walker. It may be
+// called more than once.
+func (w *TagWalker) Close() error {
+	w.Cancel()
+	w.free()
+	return nil
+}
+
+// Err returns error encountered while walking tags.
+func (w *TagWalker) Err() error {
+	return w.err
+}
+
+// Slice returns a slice holding the tags and any error encountered while
+// walking the tags.
+func (w *TagWalker) Slice() ([]*TagRef, error) {
+	s := []*TagRef{}
+	for t := range w.C {
+		s = append(s, t)
+	}
+	return s, w.Err()
+}
+
+func (w *TagWalker) cancel() {
+	close(w.cc)
+	for range w.C {
+	}
+}
+
+func (w *TagWalker) next() (*TagRef, error) {
+	if err := w.repo.acquire(); err != nil {
+		return nil, err
+	}
+	defer w.repo.release()
+
+	r, err := w.gitReferenceIterator.next()
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, nil
+	}
+	return &TagRef{r, w.repo}, nil
+}
+
+func (w *TagWalker) run(c chan<- *TagRef) {
+	defer close(c)
+
+	for {
+		tag, err := w.next()
+		if err != nil {
+			w.err = err
+			return
+		}
+		if tag == nil {
+			return
+		}
+
+		select {
+		case c <- tag:
+		case <-w.cc:
+			return
+		}
+	}
+}
+
+type gitTag struct {
+	ptr *C.git_tag
+
+	repo *gitRepository
+}
+
+func (t *gitTag) init() {
+	runtime.SetFinalizer(t, (*gitTag).free)
+}
+
+func (t *gitTag) free() {
+	runtime.SetFinalizer(t, nil)
+	C.git_tag_free(t.ptr)
+	t.ptr = nil
+}
+
+func gitTagCreate(repo *gitRepository, tagName string, target *gitObject,
+	tagger *gitSignature, message string, force bool) (OID, error) {
+
+	var oid OID
+
+	cname := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cname))
+
+	cmessage := C.CString(message)
+	defer C.free(unsafe.Pointer(cmessage))
+
+	err := unwrapErr(C.libgit2_tag_create(oid.ptr(), repo.ptr, cname, target.ptr,
+		tagger.ptr, cmessage, cbool(force)))
+	if err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
+
+func gitTagCreateLightweight(repo *gitRepository, tagName string, target *gitObject,
+	force bool) error {
+
+	var oid OID
+
+	cname := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_tag_create_lightweight(oid.ptr(), repo.ptr, cname,
+		target.ptr, cbool(force)))
+}
+
+func gitTagDelete(repo *gitRepository, tagName string) error {
+	cname := C.CString(tagName)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_tag_delete(repo.ptr, cname))
+}
+
+func gitTagID(tag *gitTag) OID {
+	return newOID(C.git_tag_id(tag.ptr))
+}
+
+func gitTagMessage(tag *gitTag) string {
+	return C.GoString(C.git_tag_message(tag.ptr))
+}
+
+func gitTagName(tag *gitTag) string {
+	return C.GoString(C.git_tag_name(tag.ptr))
+}
+
+func gitTagTagger(tag *gitTag) *gitSignature {
+	return &gitSignature{ptr: C.git_tag_tagger(tag.ptr)}
+}
+
+func gitTagTarget(tag *gitTag) (*gitObject, error) {
+	o := &gitObject{repo: tag.repo}
+
+	if err := unwrapErr(C.libgit2_tag_target(&o.ptr, tag.ptr)); err != nil {
+		return nil, err
+	}
+	o.init()
+	return o, nil
+}
+
+func gitTagTargetID(tag *gitTag) OID {
+	return newOID(C.git_tag_target_id(tag.ptr))
+}
+
+func gitTagTargetType(tag *gitTag) ObjectType {
+	return ObjectType(C.git_tag_target_type(tag.ptr))
+}
@@ -0,0 +1,61 @@
+package libgit2
+
+type tagConfig struct {
+	repo   Repository
+	name   string
+	target Object
+	tagger *Signature
+	force  bool
+}
+
+// TagOption is an option type for git tag operations.
+type TagOption func(*tagConfig)
+
+func (c *tagConfig) checkCreate() error {
+	if err := c.checkCreateLightweight(); err != nil {
+		return err
+	}
+
+	if c.tagger == nil {
+		var err error
+		if c.tagger, err = c.repo.DefaultSignature(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *tagConfig) checkCreateLightweight() error {
+	if c.target == nil {
+		tip, err := c.repo.tip()
+		if err != nil {
+			return err
+		}
+		c.target = tip
+	}
+
+	return nil
+}
+
+// ForceTag overwrites an existing tag.
+func ForceTag() TagOption {
+	return func(c *tagConfig) {
+		c.force = true
+	}
+}
+
+// TagTarget sets the object a tag points to. Tags point to the HEAD commit
+// by default.
+func TagTarget(target Object) TagOption {
+	return func(c *tagConfig) {
+		c.target = target
+	}
+}
+
+// Tagger sets the identity of the creator of an annotated tag.
+func Tagger(sig *Signature) TagOption {
+	return func(c *tagConfig) {
+		c.tagger = sig
+	}
+}
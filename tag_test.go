@@ -0,0 +1,183 @@
+package libgit2
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestCreateTag(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 1)
+
+	tip, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, message := rndstr(), rndstr()
+	tag, err := repo.CreateTag(name, message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := name, tag.Name(); want != got {
+		t.Errorf("want tag name %q, got %q", want, got)
+	}
+	if want, got := message, tag.Message(); want != got {
+		t.Errorf("want tag message %q, got %q", want, got)
+	}
+	if want, got := tip.ID().String(), tag.TargetID().String(); want != got {
+		t.Errorf("want tag target %s, got %s", want, got)
+	}
+	if want, got := ObjectCommit, tag.TargetType(); want != got {
+		t.Errorf("want target type %s, got %s", want, got)
+	}
+
+	sig, err := repo.DefaultSignature()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagger, err := tag.Tagger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := sig.Name, tagger.Name; want != got {
+		t.Errorf("want tagger %q, got %q", want, got)
+	}
+
+	target, err := tag.Target()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := target.(*Commit); !ok {
+		t.Errorf("want *Commit target, got %T", target)
+	}
+
+	if _, err := repo.CreateTag(name, message); err == nil {
+		t.Errorf("want error creating existing tag %q", name)
+	}
+	if _, err := repo.CreateTag(name, message, ForceTag()); err != nil {
+		t.Error(err)
+	}
+
+	ref, err := repo.LookupTagRef(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if annotated, err := ref.IsAnnotated(); err != nil || !annotated {
+		t.Errorf("want annotated tag, got %v (%v)", annotated, err)
+	}
+	if _, err := ref.Tag(); err != nil {
+		t.Error(err)
+	}
+	obj, err := ref.Peel(ObjectCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := tip.ID().String(), obj.ID().String(); want != got {
+		t.Errorf("want peeled commit %s, got %s", want, got)
+	}
+}
+
+func TestCreateLightweightTag(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 2)
+
+	tip, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parents, err := tip.Parents()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := rndstr()
+	ref, err := repo.CreateLightweightTag(name, TagTarget(parents[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, got := name, ref.Name(); want != got {
+		t.Errorf("want tag name %q, got %q", want, got)
+	}
+	if want, got := parents[0].ID().String(), ref.Target().String(); want != got {
+		t.Errorf("want tag target %s, got %s", want, got)
+	}
+	if annotated, err := ref.IsAnnotated(); err != nil || annotated {
+		t.Errorf("want lightweight tag, got %v (%v)", annotated, err)
+	}
+	if _, err := ref.Tag(); err == nil {
+		t.Error("want error looking up the tag object of a lightweight tag")
+	}
+
+	if err := ref.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.LookupTagRef(name); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func TestTags(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepoN(t, repo, 1)
+
+	for _, name := range []string{"v1.0", "v1.1", "v2.0"} {
+		if _, err := repo.CreateTag(name, rndstr()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := repo.CreateLightweightTag("latest"); err != nil {
+		t.Fatal(err)
+	}
+
+	for pattern, want := range map[string][]string{
+		"":    {"latest", "v1.0", "v1.1", "v2.0"},
+		"v1*": {"v1.0", "v1.1"},
+		"v3*": {},
+	} {
+		walker, err := repo.Tags(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tags, err := walker.Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := []string{}
+		for _, tag := range tags {
+			names = append(names, tag.Name())
+		}
+		sort.Strings(names)
+
+		if !reflect.DeepEqual(want, names) {
+			t.Errorf("want tags %v for pattern %q, got %v", want, pattern, names)
+		}
+	}
+
+	if err := repo.DeleteTag("v2.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	walker, err := repo.Tags("v2*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer walker.Close()
+
+	if tag, ok := <-walker.C; ok {
+		t.Errorf("want deleted tag, got %q", tag.Name())
+	}
+}
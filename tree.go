@@ -3,23 +3,161 @@ package libgit2
 //#include "libgit2.h"
 import "C"
 
-import "runtime"
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// TreeWalkMode is the order in which Tree.Walk visits the entries of a tree.
+type TreeWalkMode int
+
+const (
+	// PreOrder visits a subtree before its entries.
+	PreOrder TreeWalkMode = C.GIT_TREEWALK_PRE
+	// PostOrder visits a subtree after its entries.
+	PostOrder TreeWalkMode = C.GIT_TREEWALK_POST
+)
+
+// SkipTree is returned by a TreeWalkFunc to skip the entries of the subtree
+// it was called for. It is only effective when walking in PreOrder.
+var SkipTree = errors.New("skip this tree")
+
+// TreeWalkFunc is called by Tree.Walk for every entry. The root is the path
+// of the tree holding the entry, relative to the walked tree, and is either
+// empty or ends with a slash. Returning an error other than SkipTree stops
+// the walk, and the error is returned by Walk.
+type TreeWalkFunc func(root string, entry TreeEntry) error
 
 // Tree is the representation of a tree object.
 type Tree struct {
 	*gitTree
 }
 
+// Close releases the tree. It may be called more than once.
+func (t Tree) Close() error {
+	t.free()
+	return nil
+}
+
+// Entries returns the entries of the tree, in the order git stores them.
+func (t Tree) Entries() []TreeEntry {
+	if t.ptr == nil {
+		return nil
+	}
+	n := gitTreeEntrycount(t.gitTree)
+
+	entries := make([]TreeEntry, 0, n)
+	for i := 0; i < n; i++ {
+		entries = append(entries, newTreeEntry(gitTreeEntryByindex(t.gitTree, i)))
+	}
+	return entries
+}
+
+// EntryByPath returns the entry at a slash separated path, looking through
+// subtrees as needed.
+func (t Tree) EntryByPath(path string) (*TreeEntry, error) {
+	if err := t.acquire(); err != nil {
+		return nil, err
+	}
+	defer t.repo.release()
+
+	ptr, err := gitTreeEntryBypath(t.gitTree, path)
+	if err != nil {
+		return nil, err
+	}
+	defer C.git_tree_entry_free(ptr)
+
+	entry := newTreeEntry(ptr)
+	return &entry, nil
+}
+
+// ID is the object ID of the tree.
+func (t Tree) ID() OID {
+	if t.ptr == nil {
+		return OID{}
+	}
+	return gitTreeID(t.gitTree)
+}
+
+// Peel returns the tree itself for ObjectTree or ObjectAny, and fails for
+// any other type.
+func (t Tree) Peel(typ ObjectType) (Object, error) {
+	return peelObject((*C.git_object)(unsafe.Pointer(t.ptr)), t.repo, typ)
+}
+
+func (t Tree) String() string {
+	return t.ID().String()
+}
+
+// Type returns ObjectTree.
+func (t Tree) Type() ObjectType {
+	return ObjectTree
+}
+
+// Walk calls fn for every entry of the tree and its subtrees, in the given
+// order.
+func (t Tree) Walk(fn TreeWalkFunc, mode TreeWalkMode) error {
+	if err := t.acquire(); err != nil {
+		return err
+	}
+	defer t.repo.release()
+
+	tw := &treeWalk{fn: fn}
+
+	handle := pointerHandles.track(tw)
+	defer pointerHandles.untrack(handle)
+
+	err := gitTreeWalk(t.gitTree, mode, handle)
+	if tw.err != nil {
+		return tw.err
+	}
+	return err
+}
+
+// acquire acquires the repository of the tree for the duration of a call,
+// and fails once the tree itself is closed.
+func (t Tree) acquire() error {
+	if t.ptr == nil {
+		return errObjectClosed
+	}
+	return t.repo.acquire()
+}
+
 func lookupTree(repo Repository, oid OID) (*Tree, error) {
-	tree, err := gitTreeLookup(repo.gitRepository, oid.gitOID)
+	tree, err := gitTreeLookup(repo.gitRepository, oid)
 	if err != nil {
 		return nil, err
 	}
 	return &Tree{tree}, nil
 }
 
+// treeWalk holds the callback of a walk in progress, and the error that
+// stopped it.
+type treeWalk struct {
+	fn  TreeWalkFunc
+	err error
+}
+
+//export libgit2TreeWalk
+func libgit2TreeWalk(handle unsafe.Pointer, root *C.char, entry *C.git_tree_entry) C.int {
+	tw := pointerHandles.get(handle).(*treeWalk)
+
+	switch err := tw.fn(C.GoString(root), newTreeEntry(entry)); err {
+	case nil:
+		return 0
+	case SkipTree:
+		return 1
+	default:
+		tw.err = err
+		return C.GIT_EUSER
+	}
+}
+
 type gitTree struct {
 	ptr *C.git_tree
+
+	repo *gitRepository
 }
 
 func (t *gitTree) init() {
@@ -29,15 +167,44 @@ func (t *gitTree) init() {
 func (t *gitTree) free() {
 	runtime.SetFinalizer(t, nil)
 	C.git_tree_free(t.ptr)
+	t.ptr = nil
+}
+
+func gitTreeEntryByindex(tree *gitTree, idx int) *C.git_tree_entry {
+	return C.git_tree_entry_byindex(tree.ptr, C.size_t(idx))
 }
 
-func gitTreeLookup(repo *gitRepository, oid *gitOID) (*gitTree, error) {
-	t := new(gitTree)
+func gitTreeEntryBypath(tree *gitTree, path string) (*C.git_tree_entry, error) {
+	var ptr *C.git_tree_entry
+
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	if err := unwrapErr(C.libgit2_tree_entry_bypath(&ptr, tree.ptr, cpath)); err != nil {
+		return nil, err
+	}
+	return ptr, nil
+}
 
-	err := unwrapErr(C.libgit2_tree_lookup(&t.ptr, repo.ptr, oid.ptr))
+func gitTreeEntrycount(tree *gitTree) int {
+	return int(C.git_tree_entrycount(tree.ptr))
+}
+
+func gitTreeID(tree *gitTree) OID {
+	return newOID(C.git_tree_id(tree.ptr))
+}
+
+func gitTreeLookup(repo *gitRepository, oid OID) (*gitTree, error) {
+	t := &gitTree{repo: repo}
+
+	err := unwrapErr(C.libgit2_tree_lookup(&t.ptr, repo.ptr, oid.ptr()))
 	if err != nil {
 		return nil, err
 	}
 	t.init()
 	return t, nil
 }
+
+func gitTreeWalk(tree *gitTree, mode TreeWalkMode, handle unsafe.Pointer) error {
+	return unwrapErr(C.libgit2_tree_walk(tree.ptr, C.git_treewalk_mode(mode), handle))
+}
@@ -0,0 +1,320 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+// errTreeBuilderClosed is returned when a tree builder is used after it is
+// closed.
+var errTreeBuilderClosed = errors.New("tree builder is closed")
+
+// TreeBuilder builds a tree object in memory from a list of entries, without
+// an index or working directory.
+type TreeBuilder struct {
+	*gitTreebuilder
+}
+
+// Close releases the builder. It may be called more than once.
+func (b TreeBuilder) Close() error {
+	b.free()
+	return nil
+}
+
+// Filter removes every entry for which fn returns true.
+func (b TreeBuilder) Filter(fn func(TreeEntry) bool) {
+	if b.ptr == nil {
+		return
+	}
+
+	handle := pointerHandles.track(fn)
+	defer pointerHandles.untrack(handle)
+
+	gitTreebuilderFilter(b.gitTreebuilder, handle)
+}
+
+// Get returns the entry with the given file name, or nil if there is none.
+func (b TreeBuilder) Get(name string) *TreeEntry {
+	if b.ptr == nil {
+		return nil
+	}
+
+	ptr := gitTreebuilderGet(b.gitTreebuilder, name)
+	if ptr == nil {
+		return nil
+	}
+
+	entry := newTreeEntry(ptr)
+	return &entry
+}
+
+// Insert adds an entry for the object oid with the given file name and mode,
+// replacing any entry with the same name. The object must exist in the
+// repository.
+func (b TreeBuilder) Insert(name string, oid OID, mode FileMode) error {
+	if err := b.acquire(); err != nil {
+		return err
+	}
+	defer b.repo.release()
+
+	return gitTreebuilderInsert(b.gitTreebuilder, name, oid, mode)
+}
+
+// Len is the number of entries in the builder.
+func (b TreeBuilder) Len() int {
+	if b.ptr == nil {
+		return 0
+	}
+	return gitTreebuilderEntrycount(b.gitTreebuilder)
+}
+
+// Remove removes the entry with the given file name.
+func (b TreeBuilder) Remove(name string) error {
+	if b.ptr == nil {
+		return errTreeBuilderClosed
+	}
+	return gitTreebuilderRemove(b.gitTreebuilder, name)
+}
+
+// Write writes the entries of the builder to the repository as a tree.
+func (b TreeBuilder) Write() (*Tree, error) {
+	if err := b.acquire(); err != nil {
+		return nil, err
+	}
+	defer b.repo.release()
+
+	oid, err := gitTreebuilderWrite(b.gitTreebuilder)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := gitTreeLookup(b.repo, oid)
+	if err != nil {
+		return nil, err
+	}
+	return &Tree{tree}, nil
+}
+
+// acquire acquires the repository of the builder for the duration of a call,
+// and fails once the builder itself is closed.
+func (b TreeBuilder) acquire() error {
+	if b.ptr == nil {
+		return errTreeBuilderClosed
+	}
+	return b.repo.acquire()
+}
+
+// TreeUpdateAction is the kind of change made by a TreeUpdate.
+type TreeUpdateAction int
+
+const (
+	// TreeUpdateUpsert adds an entry, or replaces the existing entry.
+	TreeUpdateUpsert TreeUpdateAction = iota
+	// TreeUpdateRemove removes an entry.
+	TreeUpdateRemove
+)
+
+// TreeUpdate is a change to the entry at a slash separated path of a tree,
+// for use with Repository.UpdateTree.
+type TreeUpdate struct {
+	Action TreeUpdateAction
+	Path   string
+	// ID and Mode are the object and mode of an upserted entry.
+	ID   OID
+	Mode FileMode
+}
+
+func newTreeBuilder(repo Repository, base *Tree) (*TreeBuilder, error) {
+	var source *gitTree
+	if base != nil {
+		source = base.gitTree
+	}
+
+	bld, err := gitTreebuilderCreate(repo.gitRepository, source)
+	if err != nil {
+		return nil, err
+	}
+	return &TreeBuilder{bld}, nil
+}
+
+func createUpdatedTree(repo Repository, base *Tree, updates []TreeUpdate) (*Tree, error) {
+	var source *gitTree
+	if base != nil {
+		source = base.gitTree
+	}
+
+	oid, err := updateTree(repo.gitRepository, source, updates)
+	if err != nil {
+		return nil, err
+	}
+	if oid.IsZero() {
+		// every entry was removed, write the empty tree
+		bld, err := gitTreebuilderCreate(repo.gitRepository, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer bld.free()
+
+		if oid, err = gitTreebuilderWrite(bld); err != nil {
+			return nil, err
+		}
+	}
+	return lookupTree(repo, oid)
+}
+
+// updateTree applies updates to base, which may be nil for an empty tree,
+// and writes the trees leading to every updated path. It returns the ID of
+// the new tree, or the zero OID if the tree is left empty.
+func updateTree(repo *gitRepository, base *gitTree, updates []TreeUpdate) (OID, error) {
+	bld, err := gitTreebuilderCreate(repo, base)
+	if err != nil {
+		return OID{}, err
+	}
+	defer bld.free()
+
+	var dirs []string
+	subtrees := map[string][]TreeUpdate{}
+	for _, u := range updates {
+		path := strings.Trim(u.Path, "/")
+
+		i := strings.IndexByte(path, '/')
+		if i < 0 {
+			if err := applyTreeUpdate(bld, path, u); err != nil {
+				return OID{}, err
+			}
+			continue
+		}
+
+		dir := path[:i]
+		if _, ok := subtrees[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		u.Path = path[i+1:]
+		subtrees[dir] = append(subtrees[dir], u)
+	}
+
+	for _, dir := range dirs {
+		var sub *gitTree
+		if ptr := gitTreebuilderGet(bld, dir); ptr != nil && C.git_tree_entry_type(ptr) == C.GIT_OBJ_TREE {
+			if sub, err = gitTreeLookup(repo, newOID(C.git_tree_entry_id(ptr))); err != nil {
+				return OID{}, err
+			}
+		}
+
+		oid, err := updateTree(repo, sub, subtrees[dir])
+		if sub != nil {
+			sub.free()
+		}
+		if err != nil {
+			return OID{}, err
+		}
+
+		if oid.IsZero() {
+			if gitTreebuilderGet(bld, dir) != nil {
+				if err := gitTreebuilderRemove(bld, dir); err != nil {
+					return OID{}, err
+				}
+			}
+			continue
+		}
+		if err := gitTreebuilderInsert(bld, dir, oid, FileModeTree); err != nil {
+			return OID{}, err
+		}
+	}
+
+	if gitTreebuilderEntrycount(bld) == 0 {
+		return OID{}, nil
+	}
+	return gitTreebuilderWrite(bld)
+}
+
+func applyTreeUpdate(bld *gitTreebuilder, name string, u TreeUpdate) error {
+	switch u.Action {
+	case TreeUpdateUpsert:
+		return gitTreebuilderInsert(bld, name, u.ID, u.Mode)
+	case TreeUpdateRemove:
+		return gitTreebuilderRemove(bld, name)
+	}
+	return fmt.Errorf("unknown tree update action %d", u.Action)
+}
+
+//export libgit2TreebuilderFilter
+func libgit2TreebuilderFilter(handle unsafe.Pointer, entry *C.git_tree_entry) C.int {
+	fn := pointerHandles.get(handle).(func(TreeEntry) bool)
+	return cbool(fn(newTreeEntry(entry)))
+}
+
+type gitTreebuilder struct {
+	ptr *C.git_treebuilder
+
+	repo *gitRepository
+}
+
+func (b *gitTreebuilder) init() {
+	runtime.SetFinalizer(b, (*gitTreebuilder).free)
+}
+
+func (b *gitTreebuilder) free() {
+	runtime.SetFinalizer(b, nil)
+	C.git_treebuilder_free(b.ptr)
+	b.ptr = nil
+}
+
+func gitTreebuilderCreate(repo *gitRepository, source *gitTree) (*gitTreebuilder, error) {
+	b := &gitTreebuilder{repo: repo}
+
+	var csource *C.git_tree
+	if source != nil {
+		csource = source.ptr
+	}
+
+	if err := unwrapErr(C.libgit2_treebuilder_create(&b.ptr, repo.ptr, csource)); err != nil {
+		return nil, err
+	}
+	b.init()
+	return b, nil
+}
+
+func gitTreebuilderEntrycount(bld *gitTreebuilder) int {
+	return int(C.git_treebuilder_entrycount(bld.ptr))
+}
+
+func gitTreebuilderFilter(bld *gitTreebuilder, handle unsafe.Pointer) {
+	C.libgit2_treebuilder_filter(bld.ptr, handle)
+}
+
+func gitTreebuilderGet(bld *gitTreebuilder, filename string) *C.git_tree_entry {
+	cname := C.CString(filename)
+	defer C.free(unsafe.Pointer(cname))
+
+	return C.git_treebuilder_get(bld.ptr, cname)
+}
+
+func gitTreebuilderInsert(bld *gitTreebuilder, filename string, oid OID, mode FileMode) error {
+	cname := C.CString(filename)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_treebuilder_insert(nil, bld.ptr, cname, oid.ptr(),
+		C.git_filemode_t(mode)))
+}
+
+func gitTreebuilderRemove(bld *gitTreebuilder, filename string) error {
+	cname := C.CString(filename)
+	defer C.free(unsafe.Pointer(cname))
+
+	return unwrapErr(C.libgit2_treebuilder_remove(bld.ptr, cname))
+}
+
+func gitTreebuilderWrite(bld *gitTreebuilder) (OID, error) {
+	var oid OID
+	if err := unwrapErr(C.libgit2_treebuilder_write(oid.ptr(), bld.ptr)); err != nil {
+		return OID{}, err
+	}
+	return oid, nil
+}
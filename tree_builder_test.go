@@ -0,0 +1,174 @@
+package libgit2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTreeBuilder(t *testing.T) {
+	repo, err := InitBareRepository(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readme := mustCreateBlob(t, repo, "hello\n")
+	script := mustCreateBlob(t, repo, "#!/bin/sh\n")
+
+	bld, err := repo.TreeBuilder(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bld.Close()
+
+	if err := bld.Insert("README", readme, FileModeBlob); err != nil {
+		t.Fatal(err)
+	}
+	if err := bld.Insert("run", script, FileModeExecutable); err != nil {
+		t.Fatal(err)
+	}
+	if err := bld.Insert("tmp", script, FileModeBlob); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 3, bld.Len(); want != got {
+		t.Errorf("want %d entries, got %d", want, got)
+	}
+
+	if err := bld.Remove("tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if entry := bld.Get("tmp"); entry != nil {
+		t.Errorf("want removed entry, got %v", entry)
+	}
+
+	entry := bld.Get("run")
+	if entry == nil {
+		t.Fatal("want entry for run")
+	}
+	if want, got := FileModeExecutable, entry.Mode; want != got {
+		t.Errorf("want mode %s, got %s", want, got)
+	}
+
+	tree, err := bld.Write()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"README", "run"}, treeEntryNames(tree); !reflect.DeepEqual(want, got) {
+		t.Errorf("want entries %v, got %v", want, got)
+	}
+
+	bld, err = repo.TreeBuilder(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bld.Close()
+
+	bld.Filter(func(entry TreeEntry) bool {
+		return entry.Mode == FileModeExecutable
+	})
+	if want, got := 1, bld.Len(); want != got {
+		t.Errorf("want %d entries, got %d", want, got)
+	}
+
+	if err := bld.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := bld.Len(); n != 0 {
+		t.Errorf("want no entries in a closed builder, got %d", n)
+	}
+	if entry := bld.Get("run"); entry != nil {
+		t.Errorf("want no entry from a closed builder, got %v", entry)
+	}
+	if err := bld.Insert("README", readme, FileModeBlob); err != errTreeBuilderClosed {
+		t.Errorf("want error %q, got %v", errTreeBuilderClosed, err)
+	}
+	if err := bld.Remove("run"); err != errTreeBuilderClosed {
+		t.Errorf("want error %q, got %v", errTreeBuilderClosed, err)
+	}
+}
+
+func TestUpdateTree(t *testing.T) {
+	repo, err := InitBareRepository(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := mustCreateBlob(t, repo, "a\n")
+	b := mustCreateBlob(t, repo, "b\n")
+
+	tree, err := repo.UpdateTree(nil,
+		TreeUpdate{Action: TreeUpdateUpsert, Path: "a/b/c.txt", ID: a, Mode: FileModeBlob},
+		TreeUpdate{Action: TreeUpdateUpsert, Path: "a/d.txt", ID: a, Mode: FileModeBlob},
+		TreeUpdate{Action: TreeUpdateUpsert, Path: "e.txt", ID: a, Mode: FileModeBlob},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"a/b/c.txt", "a/d.txt", "e.txt"}, treeFiles(t, tree); !reflect.DeepEqual(want, got) {
+		t.Errorf("want files %v, got %v", want, got)
+	}
+
+	tree, err = repo.UpdateTree(tree,
+		TreeUpdate{Action: TreeUpdateUpsert, Path: "a/b/c.txt", ID: b, Mode: FileModeBlob},
+		TreeUpdate{Action: TreeUpdateRemove, Path: "a/d.txt"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"a/b/c.txt", "e.txt"}, treeFiles(t, tree); !reflect.DeepEqual(want, got) {
+		t.Errorf("want files %v, got %v", want, got)
+	}
+
+	entry, err := tree.EntryByPath("a/b/c.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := b.String(), entry.ID.String(); want != got {
+		t.Errorf("want blob %s, got %s", want, got)
+	}
+
+	tree, err = repo.UpdateTree(tree,
+		TreeUpdate{Action: TreeUpdateRemove, Path: "a/b/c.txt"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"e.txt"}, treeEntryNames(tree); !reflect.DeepEqual(want, got) {
+		t.Errorf("want entries %v, got %v", want, got)
+	}
+
+	if _, err := repo.UpdateTree(tree,
+		TreeUpdate{Action: TreeUpdateAction(42), Path: "a/f.txt", ID: a, Mode: FileModeBlob},
+	); err == nil {
+		t.Error("want error for an unknown tree update action")
+	}
+}
+
+func mustCreateBlob(t *testing.T, repo *Repository, data string) OID {
+	oid, err := repo.CreateBlob([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oid
+}
+
+func treeEntryNames(tree *Tree) []string {
+	var names []string
+	for _, entry := range tree.Entries() {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func treeFiles(t *testing.T, tree *Tree) []string {
+	var files []string
+	err := tree.Walk(func(root string, entry TreeEntry) error {
+		if entry.Type == ObjectBlob {
+			files = append(files, root+entry.Name)
+		}
+		return nil
+	}, PreOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
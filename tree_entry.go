@@ -0,0 +1,69 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// FileMode is the mode of an entry in a tree.
+type FileMode uint32
+
+const (
+	// FileModeTree is a subdirectory.
+	FileModeTree FileMode = C.GIT_FILEMODE_TREE
+	// FileModeBlob is a regular file.
+	FileModeBlob FileMode = C.GIT_FILEMODE_BLOB
+	// FileModeExecutable is an executable file.
+	FileModeExecutable FileMode = C.GIT_FILEMODE_BLOB_EXECUTABLE
+	// FileModeSymlink is a symbolic link, stored as a blob holding the link
+	// target.
+	FileModeSymlink FileMode = C.GIT_FILEMODE_LINK
+	// FileModeGitlink is a submodule, pointing at a commit of another
+	// repository.
+	FileModeGitlink FileMode = C.GIT_FILEMODE_COMMIT
+)
+
+// String returns the mode in octal, as printed by git ls-tree.
+func (m FileMode) String() string {
+	return fmt.Sprintf("%06o", uint32(m))
+}
+
+// fsMode converts m to the mode of a file in a checkout.
+func (m FileMode) fsMode() fs.FileMode {
+	switch m {
+	case FileModeTree, FileModeGitlink:
+		return fs.ModeDir | 0755
+	case FileModeExecutable:
+		return 0755
+	case FileModeSymlink:
+		return fs.ModeSymlink | 0777
+	default:
+		return 0644
+	}
+}
+
+// TreeEntry is an entry of a tree: a file, symlink, subdirectory or
+// submodule.
+type TreeEntry struct {
+	// Name is the file name of the entry, without any leading directories.
+	Name string
+	// ID is the object ID of the blob, tree or submodule commit.
+	ID OID
+	// Mode is the file mode of the entry.
+	Mode FileMode
+	// Type is the type of the object the entry points at.
+	Type ObjectType
+}
+
+// newTreeEntry copies an entry owned by libgit2.
+func newTreeEntry(ptr *C.git_tree_entry) TreeEntry {
+	return TreeEntry{
+		Name: C.GoString(C.git_tree_entry_name(ptr)),
+		ID:   newOID(C.git_tree_entry_id(ptr)),
+		Mode: FileMode(C.git_tree_entry_filemode(ptr)),
+		Type: ObjectType(C.git_tree_entry_type(ptr)),
+	}
+}
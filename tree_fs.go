@@ -0,0 +1,337 @@
+package libgit2
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+var (
+	errIsDir  = errors.New("is a directory")
+	errNotDir = errors.New("not a directory")
+)
+
+var (
+	_ fs.FS         = Tree{}
+	_ fs.ReadDirFS  = Tree{}
+	_ fs.ReadFileFS = Tree{}
+	_ fs.StatFS     = Tree{}
+)
+
+// Open opens a file or directory of the tree, implementing fs.FS. The
+// contents of files are read from the repository when the file is first
+// read. Symbolic links are not followed: opening one reads the link target.
+// Submodules are seen as empty directories.
+func (t Tree) Open(name string) (fs.File, error) {
+	entry, err := t.fsEntry("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Type == ObjectBlob {
+		return &treeFile{tree: t, entry: entry}, nil
+	}
+
+	entries, err := t.fsReadDir("open", name, entry)
+	if err != nil {
+		return nil, err
+	}
+	return &treeDir{tree: t, entry: entry, entries: entries}, nil
+}
+
+// ReadDir returns the entries of a directory of the tree sorted by file name,
+// implementing fs.ReadDirFS.
+func (t Tree) ReadDir(name string) ([]fs.DirEntry, error) {
+	entry, err := t.fsEntry("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Type == ObjectBlob {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+	}
+	return t.fsReadDir("readdir", name, entry)
+}
+
+// ReadFile returns the contents of a file of the tree, implementing
+// fs.ReadFileFS.
+func (t Tree) ReadFile(name string) ([]byte, error) {
+	entry, err := t.fsEntry("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Type != ObjectBlob {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errIsDir}
+	}
+
+	f := &treeFile{tree: t, entry: entry}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// Stat returns a fs.FileInfo for a file or directory of the tree,
+// implementing fs.StatFS.
+func (t Tree) Stat(name string) (fs.FileInfo, error) {
+	entry, err := t.fsEntry("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return t.fsFileInfo("stat", name, entry)
+}
+
+// fsEntry returns the entry at a fs.FS path, "." being the tree itself.
+func (t Tree) fsEntry(op, name string) (TreeEntry, error) {
+	if !fs.ValidPath(name) {
+		return TreeEntry{}, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if t.ptr == nil {
+		return TreeEntry{}, &fs.PathError{Op: op, Path: name, Err: errObjectClosed}
+	}
+	if name == "." {
+		return TreeEntry{Name: ".", ID: t.ID(), Mode: FileModeTree, Type: ObjectTree}, nil
+	}
+
+	entry, err := t.EntryByPath(name)
+	if err != nil {
+		return TreeEntry{}, fsPathError(op, name, err)
+	}
+	return *entry, nil
+}
+
+func (t Tree) fsFileInfo(op, name string, entry TreeEntry) (fs.FileInfo, error) {
+	info := &treeFileInfo{entry: entry}
+	if entry.Type != ObjectBlob {
+		return info, nil
+	}
+
+	if err := t.repo.acquire(); err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	defer t.repo.release()
+
+	odb, err := gitRepositoryODB(t.repo)
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	defer odb.free()
+
+	if info.size, _, err = gitODBReadHeader(odb, entry.ID); err != nil {
+		return nil, fsPathError(op, name, err)
+	}
+	return info, nil
+}
+
+// fsReadDir lists the entries of a subtree, sorted by name as required by
+// fs.ReadDirFS rather than in tree order.
+func (t Tree) fsReadDir(op, name string, entry TreeEntry) ([]fs.DirEntry, error) {
+	var entries []TreeEntry
+	switch entry.Mode {
+	case FileModeGitlink:
+	case FileModeTree:
+		if name == "." {
+			entries = t.Entries()
+			break
+		}
+
+		if err := t.repo.acquire(); err != nil {
+			return nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		tree, err := gitTreeLookup(t.repo, entry.ID)
+		t.repo.release()
+		if err != nil {
+			return nil, fsPathError(op, name, err)
+		}
+
+		entries = Tree{tree}.Entries()
+		tree.free()
+	}
+
+	dirents := make([]fs.DirEntry, 0, len(entries))
+	for _, e := range entries {
+		dirents = append(dirents, &treeDirEntry{tree: t, dir: name, entry: e})
+	}
+	sort.Slice(dirents, func(i, j int) bool {
+		return dirents[i].Name() < dirents[j].Name()
+	})
+	return dirents, nil
+}
+
+func fsPathError(op, name string, err error) error {
+	if isNotFound(err) {
+		err = fs.ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+// treeFileInfo describes an entry of a tree, implementing fs.FileInfo.
+type treeFileInfo struct {
+	entry TreeEntry
+	size  int64
+}
+
+// Base name of the file.
+func (fi *treeFileInfo) Name() string {
+	return fi.entry.Name
+}
+
+// Size of the file contents in bytes, zero for directories.
+func (fi *treeFileInfo) Size() int64 {
+	return fi.size
+}
+
+// File mode bits, derived from the git file mode.
+func (fi *treeFileInfo) Mode() fs.FileMode {
+	return fi.entry.Mode.fsMode()
+}
+
+// Trees do not record modification times, so ModTime is always zero.
+func (fi *treeFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+// IsDir returns true for subtrees and submodules.
+func (fi *treeFileInfo) IsDir() bool {
+	return fi.Mode().IsDir()
+}
+
+// Sys returns the TreeEntry.
+func (fi *treeFileInfo) Sys() interface{} {
+	return fi.entry
+}
+
+// treeDirEntry is an entry listed by ReadDir, implementing fs.DirEntry. The
+// size of files is only read when Info is called.
+type treeDirEntry struct {
+	tree  Tree
+	dir   string
+	entry TreeEntry
+}
+
+func (de *treeDirEntry) Name() string {
+	return de.entry.Name
+}
+
+func (de *treeDirEntry) IsDir() bool {
+	return de.Type().IsDir()
+}
+
+func (de *treeDirEntry) Type() fs.FileMode {
+	return de.entry.Mode.fsMode().Type()
+}
+
+func (de *treeDirEntry) Info() (fs.FileInfo, error) {
+	return de.tree.fsFileInfo("stat", path.Join(de.dir, de.entry.Name), de.entry)
+}
+
+// treeDir is an open directory of a tree, implementing fs.ReadDirFile.
+type treeDir struct {
+	tree    Tree
+	entry   TreeEntry
+	entries []fs.DirEntry
+	closed  bool
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) {
+	return &treeFileInfo{entry: d.entry}, nil
+}
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.Name, Err: errIsDir}
+}
+
+func (d *treeDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if d.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: d.entry.Name, Err: fs.ErrClosed}
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	if n > len(d.entries) {
+		n = len(d.entries)
+	}
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+	return entries, nil
+}
+
+func (d *treeDir) Close() error {
+	d.closed = true
+	return nil
+}
+
+// treeFile is an open file of a tree. The blob holding its contents is looked
+// up on the first read, and the file can seek so that it can be served by
+// http.FileServer.
+type treeFile struct {
+	tree   Tree
+	entry  TreeEntry
+	rd     *blobReader
+	closed bool
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) {
+	return f.tree.fsFileInfo("stat", f.entry.Name, f.entry)
+}
+
+func (f *treeFile) Read(p []byte) (int, error) {
+	if err := f.open("read"); err != nil {
+		return 0, err
+	}
+	return f.rd.Read(p)
+}
+
+func (f *treeFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.open("seek"); err != nil {
+		return 0, err
+	}
+	return f.rd.Seek(offset, whence)
+}
+
+func (f *treeFile) Close() error {
+	if f.rd != nil {
+		f.rd.Close()
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *treeFile) open(op string) error {
+	if f.closed {
+		return &fs.PathError{Op: op, Path: f.entry.Name, Err: fs.ErrClosed}
+	}
+	if f.rd != nil {
+		return nil
+	}
+
+	if err := f.tree.repo.acquire(); err != nil {
+		return &fs.PathError{Op: op, Path: f.entry.Name, Err: err}
+	}
+	defer f.tree.repo.release()
+
+	obj, err := gitObjectLookup(f.tree.repo, f.entry.ID, ObjectBlob)
+	if err != nil {
+		return fsPathError(op, f.entry.Name, err)
+	}
+
+	o, err := obj.object()
+	if err != nil {
+		return fsPathError(op, f.entry.Name, err)
+	}
+	blob := o.(*Blob)
+	f.rd = &blobReader{blob: blob.gitBlob, size: blob.Size()}
+	return nil
+}
@@ -0,0 +1,140 @@
+package libgit2
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTreeFS(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	tree := mustWriteTestTree(t, repo, map[string]string{
+		"README":      "hello\n",
+		"a-b":         rndstr(),
+		"a/b/c.go":    rndstr(),
+		"a/d.go":      rndstr(),
+		"src/main.go": rndstr(),
+	})
+
+	if err := fstest.TestFS(tree, "README", "a-b", "a/b/c.go", "a/d.go", "src/main.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := fs.ReadFile(tree, "README")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "hello\n", string(data); want != got {
+		t.Errorf("want contents %q, got %q", want, got)
+	}
+
+	info, err := fs.Stat(tree, "README")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := int64(len("hello\n")), info.Size(); want != got {
+		t.Errorf("want size %d, got %d", want, got)
+	}
+
+	if _, err := fs.Stat(tree, "missing"); !os.IsNotExist(err) {
+		t.Errorf("want not exist error, got %v", err)
+	}
+	if _, err := fs.ReadDir(tree, "README"); err == nil {
+		t.Error("want error reading a file as a directory")
+	}
+}
+
+func TestTreeFSModes(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	if err := os.MkdirAll("bin", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("bin", "run"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("bin", "run"), "run"); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := repo.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"bin/run", "run"} {
+		if err := idx.AddPath(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.Write(); err != nil {
+		t.Fatal(err)
+	}
+	popd(t)
+
+	tree := mustWriteTestTree(t, repo, map[string]string{
+		"README": rndstr(),
+	})
+
+	modes := map[string]fs.FileMode{}
+	err = fs.WalkDir(tree, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		modes[path] = info.Mode()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]fs.FileMode{
+		".":       fs.ModeDir | 0755,
+		"README":  0644,
+		"bin":     fs.ModeDir | 0755,
+		"bin/run": 0755,
+		"run":     fs.ModeSymlink | 0777,
+	}
+	if !reflect.DeepEqual(want, modes) {
+		t.Errorf("want modes %v, got %v", want, modes)
+	}
+
+	target, err := fs.ReadFile(tree, "run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "bin/run", string(target); want != got {
+		t.Errorf("want link target %q, got %q", want, got)
+	}
+}
+
+func TestCommitFS(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	mustWriteTestTree(t, repo, map[string]string{
+		"a/b.go": rndstr(),
+		"c.go":   rndstr(),
+	})
+
+	commit, err := repo.tip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := commit.FS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := fs.Glob(fsys, "*/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a/b.go"}; !reflect.DeepEqual(want, files) {
+		t.Errorf("want files %v, got %v", want, files)
+	}
+}
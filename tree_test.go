@@ -0,0 +1,183 @@
+package libgit2
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestTreeEntries(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	tree := mustWriteTestTree(t, repo, map[string]string{
+		"README":      rndstr(),
+		"bin/run":     rndstr(),
+		"src/main.go": rndstr(),
+	})
+
+	entries := tree.Entries()
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name)
+	}
+	if want := []string{"README", "bin", "src"}; !reflect.DeepEqual(want, names) {
+		t.Errorf("want entries %v, got %v", want, names)
+	}
+
+	if want, got := FileModeBlob, entries[0].Mode; want != got {
+		t.Errorf("want mode %s, got %s", want, got)
+	}
+	if want, got := ObjectBlob, entries[0].Type; want != got {
+		t.Errorf("want type %s, got %s", want, got)
+	}
+	if want, got := FileModeTree, entries[1].Mode; want != got {
+		t.Errorf("want mode %s, got %s", want, got)
+	}
+	if want, got := ObjectTree, entries[1].Type; want != got {
+		t.Errorf("want type %s, got %s", want, got)
+	}
+}
+
+func TestTreeEntryByPath(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	tree := mustWriteTestTree(t, repo, map[string]string{
+		"a/b/c.go": "package b\n",
+	})
+
+	entry, err := tree.EntryByPath("a/b/c.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "c.go", entry.Name; want != got {
+		t.Errorf("want name %q, got %q", want, got)
+	}
+
+	oid, err := repo.CreateBlob([]byte("package b\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := oid.String(), entry.ID.String(); want != got {
+		t.Errorf("want blob %s, got %s", want, got)
+	}
+
+	entry, err = tree.EntryByPath("a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := ObjectTree, entry.Type; want != got {
+		t.Errorf("want type %s, got %s", want, got)
+	}
+
+	if _, err := tree.EntryByPath("a/missing.go"); !isNotFound(err) {
+		t.Errorf("want not found error, got %v", err)
+	}
+}
+
+func TestTreeWalk(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	tree := mustWriteTestTree(t, repo, map[string]string{
+		"a/b/c.go": rndstr(),
+		"a/d.go":   rndstr(),
+		"e.go":     rndstr(),
+		"f/g.go":   rndstr(),
+	})
+
+	var pre []string
+	err := tree.Walk(func(root string, entry TreeEntry) error {
+		pre = append(pre, root+entry.Name)
+		return nil
+	}, PreOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"a", "a/b", "a/b/c.go", "a/d.go", "e.go", "f", "f/g.go"}
+	if !reflect.DeepEqual(want, pre) {
+		t.Errorf("want pre-order walk %v, got %v", want, pre)
+	}
+
+	var post []string
+	err = tree.Walk(func(root string, entry TreeEntry) error {
+		post = append(post, root+entry.Name)
+		return nil
+	}, PostOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"a/b/c.go", "a/b", "a/d.go", "a", "e.go", "f/g.go", "f"}
+	if !reflect.DeepEqual(want, post) {
+		t.Errorf("want post-order walk %v, got %v", want, post)
+	}
+
+	var files []string
+	err = tree.Walk(func(root string, entry TreeEntry) error {
+		if entry.Name == "a" {
+			return SkipTree
+		}
+		if entry.Type == ObjectBlob {
+			files = append(files, root+entry.Name)
+		}
+		return nil
+	}, PreOrder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{"e.go", "f/g.go"}
+	if !reflect.DeepEqual(want, files) {
+		t.Errorf("want files %v, got %v", want, files)
+	}
+
+	errStop := errors.New("stop")
+	err = tree.Walk(func(root string, entry TreeEntry) error {
+		return errStop
+	}, PreOrder)
+	if err != errStop {
+		t.Errorf("want error %v, got %v", errStop, err)
+	}
+}
+
+// mustWriteTestTree commits files to repo, and returns the tree of the
+// commit.
+func mustWriteTestTree(t *testing.T, repo *Repository, files map[string]string) *Tree {
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	idx, err := repo.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(files[path]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := idx.AddPath(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := idx.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := repo.Commit(Message(rndstr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj, err := commit.Peel(ObjectTree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return obj.(*Tree)
+}
@@ -31,12 +31,18 @@ const (
 	SortReverse SortMode = C.GIT_SORT_REVERSE
 )
 
-// Walker is an in-progress walk through the commits in a repo.
+// Walker is an in-progress walk through the commits in a repo. In a shallow
+// repository the walk stops at the shallow roots, which are walked as root
+// commits.
 type Walker struct {
 	*gitRevwalk
 
 	C <-chan *Commit
 
+	// shallow walks the commits of a shallow repository in place of the
+	// revwalk.
+	shallow *shallowWalk
+
 	err error
 
 	co *sync.Once
@@ -44,31 +50,89 @@ type Walker struct {
 }
 
 func newWalker(config *walkerConfig) (*Walker, error) {
+	var (
+		push, hide []OID
+		err        error
+	)
+	if config.revspec != nil {
+		if push, hide, err = config.revspec.walkCommits(config.repo.gitRepository); err != nil {
+			return nil, err
+		}
+	}
+
+	c := make(chan *Commit, config.bufSize)
+	w := &Walker{
+		C:  c,
+		co: &sync.Once{},
+		cc: make(chan struct{}),
+	}
+
+	if gitRepositoryIsShallow(config.repo.gitRepository) {
+		// the revwalk would walk past the shallow roots, so none is built
+		w.gitRevwalk = &gitRevwalk{}
+		if w.shallow, err = newWalkerShallow(config, push, hide); err != nil {
+			return nil, err
+		}
+	} else {
+		if w.gitRevwalk, err = newWalkerRevwalk(config, push, hide); err != nil {
+			return nil, err
+		}
+	}
+
+	go w.run(config.repo, c)
+	return w, nil
+}
+
+// newWalkerRevwalk returns a libgit2 revwalk from the pushed commits, or HEAD,
+// excluding the hidden commits and their ancestors.
+func newWalkerRevwalk(config *walkerConfig, push, hide []OID) (*gitRevwalk, error) {
 	r, err := gitRevwalkNew(config.repo.gitRepository)
 	if err != nil {
 		return nil, err
 	}
 
-	if config.startRef == "" {
+	if config.startRef == "" && len(push) == 0 {
 		if err = r.pushHead(); err != nil {
 			return nil, err
 		}
 	}
+	for _, oid := range push {
+		if err = r.push(oid); err != nil {
+			return nil, err
+		}
+	}
+	for _, oid := range hide {
+		if err = r.hide(oid); err != nil {
+			return nil, err
+		}
+	}
 
 	if config.sortMode != SortNone {
 		r.sorting(config.sortMode)
 	}
+	return r, nil
+}
 
-	c := make(chan *Commit, config.bufSize)
-	w := &Walker{
-		gitRevwalk: r,
-		C:          c,
-		co:         &sync.Once{},
-		cc:         make(chan struct{}),
+// newWalkerShallow returns a walk of a shallow repository from the pushed
+// commits, or HEAD, excluding the hidden commits and their ancestors.
+func newWalkerShallow(config *walkerConfig, push, hide []OID) (*shallowWalk, error) {
+	starts, err := lookupCommits(config.repo, push)
+	if err != nil {
+		return nil, err
+	}
+	if len(starts) == 0 {
+		tip, err := config.repo.tip()
+		if err != nil {
+			return nil, err
+		}
+		starts = []*Commit{tip}
 	}
 
-	go w.run(config.repo, c)
-	return w, nil
+	hidden, err := lookupCommits(config.repo, hide)
+	if err != nil {
+		return nil, err
+	}
+	return newShallowWalk(config.repo, starts, hidden, config.sortMode)
 }
 
 // Cancel aborts an in-progress walk and drains the commit channel C.
@@ -76,6 +140,14 @@ func (w *Walker) Cancel() {
 	w.co.Do(w.cancel)
 }
 
+// Close aborts the walk, if in progress, and releases the walker. It may be
+// called more than once.
+func (w *Walker) Close() error {
+	w.Cancel()
+	w.free()
+	return nil
+}
+
 // Err returns error encountered while walking commits.
 func (w *Walker) Err() error {
 	return w.err
@@ -98,13 +170,20 @@ func (w *Walker) cancel() {
 }
 
 func (w *Walker) next(repo Repository) (*Commit, error) {
-	o, err := w.gitRevwalk.next()
-	if err != nil {
+	if err := repo.acquire(); err != nil {
 		return nil, err
 	}
+	defer repo.release()
 
-	oid := OID{o}
-	if oid.isZero() {
+	if w.shallow != nil {
+		return w.shallow.next()
+	}
+
+	oid, err := w.gitRevwalk.next()
+	if err != nil {
+		return nil, err
+	}
+	if oid.IsZero() {
 		return nil, nil
 	}
 	return lookupCommit(repo, oid)
@@ -142,11 +221,24 @@ func (r *gitRevwalk) init() {
 func (r *gitRevwalk) free() {
 	runtime.SetFinalizer(r, nil)
 	C.git_revwalk_free(r.ptr)
+	r.ptr = nil
+}
+
+func (r *gitRevwalk) hide(oid OID) error {
+	return unwrapErr(C.libgit2_revwalk_hide(r.ptr, oid.ptr()))
+}
+
+// next returns the zero OID once the walk is over.
+func (r *gitRevwalk) next() (OID, error) {
+	var oid OID
+	if err := unwrapErr(C.libgit2_revwalk_next(oid.ptr(), r.ptr)); err != nil {
+		return OID{}, err
+	}
+	return oid, nil
 }
 
-func (r *gitRevwalk) next() (*gitOID, error) {
-	oid := &gitOID{ptr: &C.git_oid{}}
-	return oid, unwrapErr(C.libgit2_revwalk_next(oid.ptr, r.ptr))
+func (r *gitRevwalk) push(oid OID) error {
+	return unwrapErr(C.libgit2_revwalk_push(r.ptr, oid.ptr()))
 }
 
 func (r *gitRevwalk) pushHead() error {
@@ -6,6 +6,7 @@ type walkerConfig struct {
 	startRef string
 	bufSize  int
 	sortMode SortMode
+	revspec  *Revspec
 }
 
 func (c *walkerConfig) check() error {
@@ -22,6 +23,14 @@ func BufferSize(n int) WalkerOption {
 	}
 }
 
+// Range walks the commits of a revspec returned by Repository.RevParseRange
+// instead of the history of HEAD.
+func Range(rs *Revspec) WalkerOption {
+	return func(c *walkerConfig) {
+		c.revspec = rs
+	}
+}
+
 // Sorting sets the sort mode of the walker.
 func Sorting(mode SortMode) WalkerOption {
 	return func(c *walkerConfig) {
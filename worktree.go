@@ -0,0 +1,805 @@
+package libgit2
+
+//#include "libgit2.h"
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+var (
+	errWorktreeExists = errors.New("worktree already exists")
+	errWorktreeLocked = errors.New("worktree is locked")
+	errWorktreeValid  = errors.New("worktree is valid")
+)
+
+// validateWorktreeName rejects names that would not resolve to a direct
+// child of the worktrees directory of the repository.
+func validateWorktreeName(name string) error {
+	if name == "" || name == "." || name == ".." ||
+		strings.ContainsAny(name, "/"+string(filepath.Separator)) {
+		return fmt.Errorf("invalid worktree name %q", name)
+	}
+	return nil
+}
+
+// Worktree is a linked working tree of a repository, as created by
+// "git worktree add". Its administrative files live in the worktrees
+// directory of the repository.
+type Worktree struct {
+	name   string
+	gitdir string
+}
+
+func newWorktree(repo Repository, name string) *Worktree {
+	return &Worktree{
+		name:   name,
+		gitdir: filepath.Join(repo.Path(), "worktrees", name),
+	}
+}
+
+func addWorktree(config *worktreeConfig) (wt *Worktree, err error) {
+	wt = newWorktree(config.repo, config.name)
+	gitdir := wt.gitdir
+
+	if _, err := os.Stat(gitdir); err == nil {
+		return nil, errWorktreeExists
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(config.path)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := config.branch
+	if branch == nil && config.ref != nil && isLocalBranch(config.ref.Name()) {
+		branch = &Branch{config.ref.gitReference, branchLocal, config.repo}
+	}
+
+	var (
+		head   string
+		target OID
+	)
+	switch {
+	case branch != nil:
+		name := gitReferenceName(branch.gitReference)
+		at, err := checkedOutAt(config.repo, name)
+		if err != nil {
+			return nil, err
+		}
+		if at != "" {
+			return nil, fmt.Errorf("branch %s is already checked out at %s", name, at)
+		}
+		head, target = "ref: "+name, gitReferenceTarget(branch.gitReference)
+	case config.ref != nil:
+		obj, err := gitReferencePeel(config.ref.gitReference, config.repo.gitRepository, ObjectCommit)
+		if err != nil {
+			return nil, err
+		}
+		target = gitObjectID(obj)
+		obj.free()
+		head = target.String()
+	default:
+		if branch, err = config.repo.CreateBranch(config.name); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err != nil {
+				gitBranchDelete(branch.gitReference)
+			}
+		}()
+		head, target = "ref: "+gitReferenceName(branch.gitReference), gitReferenceTarget(branch.gitReference)
+	}
+
+	commit, err := lookupCommit(config.repo, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// remove the directories created for a worktree that could not be set
+	// up, leaving no half-written administrative files behind
+	var created []string
+	defer func() {
+		if err != nil {
+			for _, dir := range created {
+				os.RemoveAll(dir)
+			}
+		}
+	}()
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		created = append(created, path)
+	}
+	if err = os.MkdirAll(path, 0777); err != nil {
+		return nil, err
+	}
+	created = append(created, gitdir)
+	if err = os.MkdirAll(gitdir, 0777); err != nil {
+		return nil, err
+	}
+
+	files := map[string]string{
+		filepath.Join(gitdir, "commondir"): "../..",
+		filepath.Join(gitdir, "gitdir"):    filepath.Join(path, ".git"),
+		filepath.Join(gitdir, "HEAD"):      head,
+		filepath.Join(path, ".git"):        "gitdir: " + gitdir,
+	}
+	if config.lock {
+		files[filepath.Join(gitdir, "locked")] = config.lockReason
+	}
+	for file, data := range files {
+		if err = ioutil.WriteFile(file, []byte(data+"\n"), 0666); err != nil {
+			return nil, err
+		}
+	}
+
+	err = checkoutTreeTo(config.repo, commit, path, filepath.Join(gitdir, "index"))
+	if err != nil {
+		return nil, err
+	}
+	return wt, nil
+}
+
+// checkedOutAt returns the working directory in which a local branch is
+// checked out, either the main one or that of a linked worktree, or an empty
+// string if the branch is not checked out.
+func checkedOutAt(repo Repository, name string) (string, error) {
+	if !gitRepositoryIsBare(repo.gitRepository) {
+		head, err := gitReferenceLookup(repo.gitRepository, "HEAD")
+		if err != nil && !isNotFound(err) {
+			return "", err
+		}
+		if err == nil {
+			target := gitReferenceSymbolicTarget(head)
+			head.free()
+
+			if target == name {
+				return gitRepositoryWorkdir(repo.gitRepository), nil
+			}
+		}
+	}
+
+	worktrees, err := repositoryWorktrees(repo)
+	if err != nil {
+		return "", err
+	}
+	for _, wt := range worktrees {
+		head, err := readWorktreeFile(filepath.Join(wt.gitdir, "HEAD"))
+		if err != nil || head != "ref: "+name {
+			continue
+		}
+		if path, err := wt.Path(); err == nil {
+			return path, nil
+		}
+		return wt.gitdir, nil
+	}
+	return "", nil
+}
+
+func isLocalBranch(name string) bool {
+	return strings.HasPrefix(name, "refs/heads/")
+}
+
+func lookupWorktree(repo Repository, name string) (*Worktree, error) {
+	if err := validateWorktreeName(name); err != nil {
+		return nil, err
+	}
+
+	wt := newWorktree(repo, name)
+	if _, err := os.Stat(wt.gitdir); err != nil {
+		return nil, err
+	}
+	return wt, nil
+}
+
+func repositoryWorktrees(repo Repository) ([]*Worktree, error) {
+	infos, err := ioutil.ReadDir(filepath.Join(repo.Path(), "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	worktrees := []*Worktree{}
+	for _, info := range infos {
+		if info.IsDir() {
+			worktrees = append(worktrees, newWorktree(repo, info.Name()))
+		}
+	}
+	return worktrees, nil
+}
+
+// IsLocked reports whether the worktree is locked, and the reason it was
+// locked with.
+func (w Worktree) IsLocked() (bool, string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(w.gitdir, "locked"))
+	if os.IsNotExist(err) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	return true, strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// commonDir returns the path of the repository the worktree belongs to.
+func (w Worktree) commonDir() (string, error) {
+	commondir, err := readWorktreeFile(filepath.Join(w.gitdir, "commondir"))
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(commondir) {
+		commondir = filepath.Join(w.gitdir, commondir)
+	}
+	return commondir, nil
+}
+
+// Lock locks the worktree so it is not pruned, recording reason as the
+// explanation.
+func (w Worktree) Lock(reason string) error {
+	locked, _, err := w.IsLocked()
+	if err != nil {
+		return err
+	}
+	if locked {
+		return errWorktreeLocked
+	}
+
+	return ioutil.WriteFile(filepath.Join(w.gitdir, "locked"),
+		[]byte(reason+"\n"), 0666)
+}
+
+// Name is the name of the worktree.
+func (w Worktree) Name() string {
+	return w.name
+}
+
+// Path returns the file path of the working directory of the worktree.
+func (w Worktree) Path() (string, error) {
+	gitfile, err := readWorktreeFile(filepath.Join(w.gitdir, "gitdir"))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(gitfile), nil
+}
+
+// Prune removes the administrative files of the worktree. Unless the
+// PruneValid and PruneLocked options are given, only invalid, unlocked
+// worktrees are pruned.
+func (w Worktree) Prune(options ...PruneOption) error {
+	if err := validateWorktreeName(w.name); err != nil {
+		return err
+	}
+	if filepath.Base(filepath.Dir(w.gitdir)) != "worktrees" || filepath.Base(w.gitdir) != w.name {
+		return fmt.Errorf("worktree %q: %s is not in a worktrees directory", w.name, w.gitdir)
+	}
+
+	config := &pruneConfig{}
+	for _, opt := range options {
+		opt(config)
+	}
+
+	if !config.valid && w.Validate() == nil {
+		return errWorktreeValid
+	}
+
+	if !config.locked {
+		locked, _, err := w.IsLocked()
+		if err != nil {
+			return err
+		}
+		if locked {
+			return errWorktreeLocked
+		}
+	}
+
+	if config.workingTree {
+		if path, err := w.Path(); err == nil {
+			if err := os.RemoveAll(path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return os.RemoveAll(w.gitdir)
+}
+
+// Unlock removes the lock on the worktree, if any.
+func (w Worktree) Unlock() error {
+	err := os.Remove(filepath.Join(w.gitdir, "locked"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Validate checks that the administrative files and the working directory of
+// the worktree are intact.
+func (w Worktree) Validate() error {
+	for _, file := range []string{"commondir", "gitdir", "HEAD"} {
+		if _, err := os.Stat(filepath.Join(w.gitdir, file)); err != nil {
+			return fmt.Errorf("worktree %q: missing %s", w.name, file)
+		}
+	}
+
+	commondir, err := w.commonDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(commondir); err != nil {
+		return fmt.Errorf("worktree %q: missing common directory %s", w.name, commondir)
+	}
+
+	path, err := w.Path()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("worktree %q: missing working directory %s", w.name, path)
+	}
+
+	return nil
+}
+
+func openWorktree(wt *Worktree) (*Repository, error) {
+	commondir, err := wt.commonDir()
+	if err != nil {
+		return nil, err
+	}
+	path, err := wt.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := gitRepositoryOpen(commondir)
+	if err != nil {
+		return nil, err
+	}
+	if err := gitRepositorySetWorkdir(r, path, false); err != nil {
+		return nil, err
+	}
+
+	idx, err := gitIndexOpen(filepath.Join(wt.gitdir, "index"))
+	if err != nil {
+		return nil, err
+	}
+	defer idx.free()
+	gitRepositorySetIndex(r, idx)
+
+	if err := setWorktreeRefdb(r, wt.gitdir); err != nil {
+		return nil, err
+	}
+	return &Repository{r}, nil
+}
+
+// setWorktreeRefdb replaces the reference database of a repository opened
+// from a linked worktree. References private to the worktree, such as HEAD,
+// are read from its administrative directory, and all others from the
+// common directory.
+func setWorktreeRefdb(r *gitRepository, gitdir string) error {
+	refdb, err := gitRefdbNew(r)
+	if err != nil {
+		return err
+	}
+	defer refdb.free()
+
+	var common *C.git_refdb_backend
+	if err := unwrapErr(C.libgit2_refdb_backend_fs(&common, r.ptr)); err != nil {
+		return err
+	}
+
+	refs := &worktreeRefs{gitdir: gitdir, repo: r.ptr, locks: map[string]*os.File{}}
+	handle := pointerHandles.track(refs)
+	worktree := C.libgit2_refdb_backend_new(handle)
+	if worktree == nil {
+		pointerHandles.untrack(handle)
+		C.libgit2_refdb_backend_free(common)
+		return errors.New("out of memory")
+	}
+
+	ptr := C.libgit2_worktree_refdb_new(common, worktree)
+	if ptr == nil {
+		C.libgit2_refdb_backend_free(common)
+		C.libgit2_refdb_backend_free(worktree)
+		return errors.New("out of memory")
+	}
+
+	if err := gitRefdbSetBackend(refdb, ptr); err != nil {
+		C.libgit2_refdb_backend_free(ptr)
+		return err
+	}
+
+	gitRepositorySetRefdb(r, refdb)
+	r.customRefdb = true
+	return nil
+}
+
+// worktreeRefs is the RefBackend for the references private to a linked
+// worktree, stored as loose files in its administrative directory along with
+// their logs. Files are locked with <name>.lock files, as git does, so that
+// git processes working on the same worktree are kept out.
+type worktreeRefs struct {
+	gitdir string
+
+	// repo resolves the targets of symbolic references for their logs. It
+	// owns the backend, so it is not tracked by the Go runtime.
+	repo *C.git_repository
+
+	mu    sync.Mutex
+	locks map[string]*os.File
+}
+
+func (r *worktreeRefs) Exists(name string) (bool, error) {
+	_, err := r.Lookup(name)
+	if err == ErrNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *worktreeRefs) Lookup(name string) (RefRecord, error) {
+	data, err := readWorktreeFile(r.path(name))
+	if os.IsNotExist(err) {
+		return RefRecord{}, ErrNotFound
+	}
+	if err != nil {
+		return RefRecord{}, err
+	}
+
+	if strings.HasPrefix(data, "ref: ") {
+		return RefRecord{Name: name, SymbolicTarget: strings.TrimPrefix(data, "ref: ")}, nil
+	}
+
+	oid, err := ParseOID(data)
+	if err != nil {
+		return RefRecord{}, err
+	}
+	return RefRecord{Name: name, Target: oid}, nil
+}
+
+// Iterate returns no references: iterations are answered by the common
+// directory, as git does not list per-worktree references either.
+func (r *worktreeRefs) Iterate(glob string) ([]RefRecord, error) {
+	return nil, nil
+}
+
+func (r *worktreeRefs) Write(ref RefRecord, force bool, who *Signature, message string, old *OID, oldTarget string) error {
+	lock, err := lockWorktreeFile(r.path(ref.Name))
+	if err != nil {
+		return err
+	}
+
+	cur, err := r.Lookup(ref.Name)
+	if err != nil && err != ErrNotFound {
+		rollbackWorktreeFile(lock)
+		return err
+	}
+	exists := err == nil
+
+	switch {
+	case exists && !force:
+		err = ErrExists
+	case old != nil && (!exists || cur.IsSymbolic() || cur.Target != *old):
+		err = ErrModified
+	case oldTarget != "" && (!exists || cur.SymbolicTarget != oldTarget):
+		err = ErrModified
+	default:
+		return r.commit(lock, cur, ref, who != nil, who, message)
+	}
+
+	rollbackWorktreeFile(lock)
+	return err
+}
+
+func (r *worktreeRefs) Rename(oldName, newName string, force bool, who *Signature, message string) (RefRecord, error) {
+	return RefRecord{}, fmt.Errorf("cannot rename per-worktree reference %s", oldName)
+}
+
+func (r *worktreeRefs) Delete(name string, old *OID, oldTarget string) error {
+	lock, err := lockWorktreeFile(r.path(name))
+	if err != nil {
+		return err
+	}
+	defer rollbackWorktreeFile(lock)
+
+	cur, err := r.Lookup(name)
+	if err != nil {
+		return err
+	}
+	if old != nil && (cur.IsSymbolic() || cur.Target != *old) {
+		return ErrModified
+	}
+	if oldTarget != "" && cur.SymbolicTarget != oldTarget {
+		return ErrModified
+	}
+	return os.Remove(r.path(name))
+}
+
+func (r *worktreeRefs) Compress() error { return nil }
+
+func (r *worktreeRefs) HasLog(name string) bool {
+	_, err := os.Stat(r.logPath(name))
+	return err == nil
+}
+
+func (r *worktreeRefs) EnsureLog(name string) error {
+	path := r.logPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func (r *worktreeRefs) RenameLog(oldName, newName string) error {
+	path := r.logPath(newName)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	return os.Rename(r.logPath(oldName), path)
+}
+
+func (r *worktreeRefs) DeleteLog(name string) error {
+	if err := os.Remove(r.logPath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *worktreeRefs) ReadLog(name string) ([]ReflogEntry, error) {
+	data, err := ioutil.ReadFile(r.logPath(name))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ReflogEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+
+		entry, err := parseReflogLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("log of %s: %v", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (r *worktreeRefs) WriteLog(name string, entries []ReflogEntry) error {
+	path := r.logPath(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+
+	lock, err := lockWorktreeFile(path)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	for _, entry := range entries {
+		data = append(data, formatReflogLine(entry)...)
+	}
+	return commitWorktreeFile(lock, path, data)
+}
+
+func (r *worktreeRefs) Lock(name string) error {
+	lock, err := lockWorktreeFile(r.path(name))
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.locks[name] = lock
+	return nil
+}
+
+func (r *worktreeRefs) Unlock(name string, ref *RefRecord, updateLog bool, who *Signature, message string) error {
+	r.mu.Lock()
+	lock, ok := r.locks[name]
+	delete(r.locks, name)
+	r.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("per-worktree reference %s is not locked", name)
+	}
+	if ref == nil {
+		rollbackWorktreeFile(lock)
+		return nil
+	}
+
+	cur, err := r.Lookup(name)
+	if err != nil && err != ErrNotFound {
+		rollbackWorktreeFile(lock)
+		return err
+	}
+	return r.commit(lock, cur, *ref, updateLog, who, message)
+}
+
+func (r *worktreeRefs) path(name string) string {
+	return filepath.Join(r.gitdir, filepath.FromSlash(name))
+}
+
+func (r *worktreeRefs) logPath(name string) string {
+	return filepath.Join(r.gitdir, "logs", filepath.FromSlash(name))
+}
+
+// commit stores ref through its held lock file, replacing cur, and logs the
+// update when asked to. As with git, HEAD is always logged, while other
+// references are only logged once they have a log.
+func (r *worktreeRefs) commit(lock *os.File, cur, ref RefRecord, updateLog bool, who *Signature, message string) error {
+	oldID := r.resolve(cur)
+
+	data := ref.Target.String()
+	if ref.IsSymbolic() {
+		data = "ref: " + ref.SymbolicTarget
+	}
+	if err := commitWorktreeFile(lock, r.path(ref.Name), []byte(data+"\n")); err != nil {
+		return err
+	}
+
+	if !updateLog || who == nil || (ref.Name != "HEAD" && !r.HasLog(ref.Name)) {
+		return nil
+	}
+	return r.appendLog(ref.Name, ReflogEntry{
+		Old:       oldID,
+		New:       r.resolve(ref),
+		Committer: who,
+		Message:   message,
+	})
+}
+
+func (r *worktreeRefs) appendLog(name string, entry ReflogEntry) error {
+	if err := r.EnsureLog(name); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(r.logPath(name), os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(formatReflogLine(entry)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// resolve returns the object a reference points at, following a symbolic
+// reference through the repository. Unborn and missing references resolve
+// to the zero OID.
+func (r *worktreeRefs) resolve(ref RefRecord) OID {
+	if !ref.IsSymbolic() {
+		return ref.Target
+	}
+
+	ctarget := C.CString(ref.SymbolicTarget)
+	defer C.free(unsafe.Pointer(ctarget))
+
+	var oid OID
+	if err := unwrapErr(C.libgit2_reference_name_to_id(oid.ptr(), r.repo, ctarget)); err != nil {
+		return OID{}
+	}
+	return oid
+}
+
+// formatReflogLine formats a log entry the way git stores it:
+// "<old> <new> <name> <<email>> <time> <zone>\t<message>\n".
+func formatReflogLine(entry ReflogEntry) string {
+	sig := entry.Committer
+	msg := strings.Replace(entry.Message, "\n", " ", -1)
+
+	return fmt.Sprintf("%s %s %s <%s> %d %s\t%s\n", entry.Old, entry.New,
+		sig.Name, sig.Email, sig.When.Unix(), sig.When.Format("-0700"), msg)
+}
+
+func parseReflogLine(line string) (ReflogEntry, error) {
+	var entry ReflogEntry
+
+	head, msg := line, ""
+	if i := strings.IndexByte(line, '\t'); i >= 0 {
+		head, msg = line[:i], line[i+1:]
+	}
+	entry.Message = msg
+
+	fields := strings.SplitN(head, " ", 3)
+	lt, gt := strings.IndexByte(head, '<'), strings.LastIndexByte(head, '>')
+	if len(fields) < 3 || lt < 0 || gt < lt {
+		return entry, fmt.Errorf("malformed entry %q", line)
+	}
+
+	var err error
+	if entry.Old, err = ParseOID(fields[0]); err != nil {
+		return entry, err
+	}
+	if entry.New, err = ParseOID(fields[1]); err != nil {
+		return entry, err
+	}
+
+	name := strings.TrimSpace(head[len(fields[0])+len(fields[1])+2 : lt])
+	email := head[lt+1 : gt]
+
+	var (
+		secs int64
+		zone string
+	)
+	if _, err := fmt.Sscanf(head[gt+1:], "%d %s", &secs, &zone); err != nil {
+		return entry, fmt.Errorf("malformed entry %q", line)
+	}
+	zt, err := time.Parse("-0700", zone)
+	if err != nil {
+		return entry, err
+	}
+	_, offset := zt.Zone()
+
+	sig, err := gitSignatureNew(name, email, time.Unix(secs, 0).In(time.FixedZone("", offset)))
+	if err != nil {
+		return entry, err
+	}
+	entry.Committer = &Signature{sig}
+	return entry, nil
+}
+
+// lockWorktreeFile takes the lock on a file by creating <path>.lock, failing
+// with ErrLocked when the lock is already held.
+func lockWorktreeFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path+".lock", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if os.IsExist(err) {
+		return nil, ErrLocked
+	}
+	return f, err
+}
+
+// commitWorktreeFile writes data to a held lock file and renames it over
+// path, releasing the lock.
+func commitWorktreeFile(lock *os.File, path string, data []byte) error {
+	_, err := lock.Write(data)
+	if cerr := lock.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(lock.Name(), path)
+	}
+	if err != nil {
+		os.Remove(lock.Name())
+	}
+	return err
+}
+
+// rollbackWorktreeFile releases a held lock file without changing the locked
+// file.
+func rollbackWorktreeFile(lock *os.File) {
+	lock.Close()
+	os.Remove(lock.Name())
+}
+
+func readWorktreeFile(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,66 @@
+package libgit2
+
+import "errors"
+
+type worktreeConfig struct {
+	repo Repository
+	name string
+	path string
+
+	branch     *Branch
+	ref        *Reference
+	lock       bool
+	lockReason string
+}
+
+func (c *worktreeConfig) check() error {
+	if c.branch != nil && c.ref != nil {
+		return errors.New("WorktreeBranch and WorktreeReference cannot be combined")
+	}
+	return validateWorktreeName(c.name)
+}
+
+// WorktreeOption is an option type for adding a worktree.
+type WorktreeOption func(*worktreeConfig)
+
+// WorktreeBranch checks out an existing local branch in the new worktree.
+// The branch must not be checked out in another working directory. By
+// default a new branch named after the worktree is created at HEAD.
+func WorktreeBranch(branch *Branch) WorktreeOption {
+	return func(c *worktreeConfig) {
+		c.branch = branch
+	}
+}
+
+// WorktreeReference checks out the commit a reference points to in the new
+// worktree, with a detached HEAD. A local branch is checked out as with
+// WorktreeBranch instead.
+func WorktreeReference(ref *Reference) WorktreeOption {
+	return func(c *worktreeConfig) {
+		c.ref = ref
+	}
+}
+
+// WorktreeLock locks the new worktree with the given reason.
+func WorktreeLock(reason string) WorktreeOption {
+	return func(c *worktreeConfig) {
+		c.lock = true
+		c.lockReason = reason
+	}
+}
+
+type pruneConfig struct {
+	valid, locked, workingTree bool
+}
+
+// PruneOption is an option type for pruning a worktree.
+type PruneOption func(*pruneConfig)
+
+// PruneLocked prunes the worktree even if it is locked.
+func PruneLocked(c *pruneConfig) { c.locked = true }
+
+// PruneValid prunes the worktree even if it is still valid.
+func PruneValid(c *pruneConfig) { c.valid = true }
+
+// PruneWorkingTree also removes the working directory of the worktree.
+func PruneWorkingTree(c *pruneConfig) { c.workingTree = true }
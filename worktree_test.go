@@ -0,0 +1,425 @@
+package libgit2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAddWorktree(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	f := mustSeedTestFile(t, repo)
+	mustCommitFile(t, repo, f)
+
+	name := rndstr()
+	path, err := filepath.Abs(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := repo.AddWorktree(name, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := wt.Path(); err != nil {
+		t.Fatal(err)
+	} else if got != path {
+		t.Errorf("want worktree path %q, got %q", path, got)
+	}
+
+	if err := wt.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(path, f)); err != nil {
+		t.Errorf("want checked out file %q: %s", f, err)
+	}
+
+	head, err := ioutil.ReadFile(filepath.Join(repo.Path(), "worktrees", name, "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ref: refs/heads/" + name + "\n"; string(head) != want {
+		t.Errorf("want worktree HEAD %q, got %q", want, head)
+	}
+
+	if _, err := repo.LocalBranch(name); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := repo.AddWorktree(name, path); err != errWorktreeExists {
+		t.Errorf("want error %q, got %v", errWorktreeExists, err)
+	}
+}
+
+func TestAddWorktreeBranch(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	branch, err := repo.CreateBranch(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := branch.Name()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := repo.AddWorktree(rndstr(), rndstr(), WorktreeBranch(branch))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := ioutil.ReadFile(filepath.Join(repo.Path(), "worktrees", wt.Name(), "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "ref: refs/heads/" + name + "\n"; string(head) != want {
+		t.Errorf("want worktree HEAD %q, got %q", want, head)
+	}
+}
+
+func TestAddWorktreeCheckedOut(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	master, err := repo.LocalBranch("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name, path := rndstr(), rndstr()
+	if _, err := repo.AddWorktree(name, path, WorktreeBranch(master)); err == nil {
+		t.Error("want error checking out the branch of the main working tree")
+	}
+	if _, err := os.Stat(filepath.Join(repo.Path(), "worktrees", name)); !os.IsNotExist(err) {
+		t.Errorf("want no worktree directory left behind, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("want no working directory left behind, got %v", err)
+	}
+
+	wt, err := repo.AddWorktree(rndstr(), rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	branch, err := repo.LocalBranch(wt.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.AddWorktree(rndstr(), rndstr(), WorktreeBranch(branch)); err == nil {
+		t.Error("want error checking out the branch of another worktree")
+	}
+}
+
+func TestAddWorktreeReference(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.LookupCommit(*head.target())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.CreateLightweightTag("v1.0", TagTarget(commit)); err != nil {
+		t.Fatal(err)
+	}
+	_, tag, err := repo.RevParse("v1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := repo.AddWorktree(rndstr(), rndstr(), WorktreeReference(tag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(repo.Path(), "worktrees", wt.Name(), "HEAD"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := commit.ID().String() + "\n"; string(data) != want {
+		t.Errorf("want detached worktree HEAD %q, got %q", want, data)
+	}
+}
+
+func TestOpenFromWorktree(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	path, err := filepath.Abs(rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.AddWorktree(rndstr(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wtRepo, err := OpenFromWorktree(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wtRepo.Close()
+
+	if want, got := path, filepath.Clean(wtRepo.Workdir()); want != got {
+		t.Errorf("want workdir %q, got %q", want, got)
+	}
+
+	head, err := wtRepo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "refs/heads/"+wt.Name(), head.Name(); want != got {
+		t.Errorf("want worktree HEAD %s, got %s", want, got)
+	}
+
+	commit, err := wtRepo.Commit(AllowEmpty, Message(rndstr()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	branch, err := repo.LocalBranch(wt.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := commit.ID(), gitReferenceTarget(branch.gitReference); want != got {
+		t.Errorf("want worktree branch at %s, got %s", want, got)
+	}
+
+	head, err = repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "refs/heads/master", head.Name(); want != got {
+		t.Errorf("want main HEAD %s, got %s", want, got)
+	}
+}
+
+func TestOpenFromWorktreeHeadLog(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tip := *head.target()
+
+	wt, err := repo.AddWorktree(rndstr(), rndstr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wtRepo, err := OpenFromWorktree(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wtRepo.Close()
+
+	lock := filepath.Join(wt.gitdir, "HEAD.lock")
+	if err := ioutil.WriteFile(lock, nil, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := wtRepo.SetHeadDetached(tip); err == nil {
+		t.Error("want error moving HEAD while HEAD.lock exists")
+	}
+	if err := os.Remove(lock); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wtRepo.SetHeadDetached(tip); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lock); !os.IsNotExist(err) {
+		t.Errorf("want HEAD.lock released, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(wt.gitdir, "logs", "HEAD")); err != nil {
+		t.Errorf("want HEAD log in worktree: %v", err)
+	}
+	obj, _, err := wtRepo.RevParse("HEAD@{0}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := tip, obj.ID(); want != got {
+		t.Errorf("want HEAD log entry %s, got %s", want, got)
+	}
+}
+
+func TestWorktrees(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	want := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		wt, err := repo.AddWorktree(rndstr(), rndstr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[wt.Name()] = true
+	}
+
+	worktrees, err := repo.Worktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != len(worktrees) {
+		t.Errorf("want %d worktrees, got %d", len(want), len(worktrees))
+	}
+	for _, wt := range worktrees {
+		if !want[wt.Name()] {
+			t.Errorf("unexpected worktree %q", wt.Name())
+		}
+	}
+}
+
+func TestWorktreeLock(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	name := rndstr()
+	if _, err := repo.AddWorktree(name, rndstr(), WorktreeLock("on a usb stick")); err != nil {
+		t.Fatal(err)
+	}
+
+	wt, err := repo.LookupWorktree(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locked, reason, err := wt.IsLocked()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked || reason != "on a usb stick" {
+		t.Errorf("want worktree locked with reason %q, got %t %q", "on a usb stick", locked, reason)
+	}
+
+	if err := wt.Lock("again"); err != errWorktreeLocked {
+		t.Errorf("want error %q, got %v", errWorktreeLocked, err)
+	}
+
+	if err := wt.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _, err = wt.IsLocked(); err != nil {
+		t.Fatal(err)
+	} else if locked {
+		t.Error("want worktree unlocked")
+	}
+}
+
+func TestWorktreePrune(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	path := rndstr()
+	wt, err := repo.AddWorktree(rndstr(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := wt.Prune(); err != errWorktreeValid {
+		t.Errorf("want error %q, got %v", errWorktreeValid, err)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Validate(); err == nil || !strings.Contains(err.Error(), "missing working directory") {
+		t.Errorf("want missing working directory error, got %v", err)
+	}
+
+	if err := wt.Lock(""); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Prune(); err != errWorktreeLocked {
+		t.Errorf("want error %q, got %v", errWorktreeLocked, err)
+	}
+
+	if err := wt.Prune(PruneLocked); err != nil {
+		t.Fatal(err)
+	}
+
+	worktrees, err := repo.Worktrees()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(worktrees) != 0 {
+		t.Errorf("want no worktrees, got %d", len(worktrees))
+	}
+}
+
+func TestWorktreeInvalidName(t *testing.T) {
+	repo := mustInitTestRepo(t)
+	pushd(t, repo.Workdir())
+	defer popd(t)
+
+	mustSeedRepo(t, repo)
+
+	for _, name := range []string{"", ".", "..", "a/b", "../" + rndstr()} {
+		if _, err := repo.AddWorktree(name, rndstr()); err == nil {
+			t.Errorf("%q: want error adding worktree", name)
+		}
+		if _, err := repo.LookupWorktree(name); err == nil {
+			t.Errorf("%q: want error looking up worktree", name)
+		}
+	}
+
+	wt := newWorktree(*repo, "..")
+	if err := wt.Prune(PruneValid, PruneLocked); err == nil {
+		t.Error("want error pruning a worktree outside the worktrees directory")
+	}
+	if _, err := os.Stat(repo.Path()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustCommitFile(t *testing.T, repo *Repository, path string) {
+	idx, err := repo.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.AddPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Commit(Message(rndstr())); err != nil {
+		t.Fatal(err)
+	}
+}